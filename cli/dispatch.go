@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/abiosoft/ishell"
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/flynn-archive/go-shlex"
+)
+
+func findCmd(name string) tcli.Cmd {
+	for _, cmd := range RegisteredCmds {
+		if cmd.Name() == name {
+			return cmd
+		}
+		for _, alias := range cmd.Alias() {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// runCmdLine runs a command line as if it was typed into the shell.
+// ishell.Shell.Process can't be used here: it keeps the raw args of the
+// last line read by the shell, while handlers read string literals from them.
+func runCmdLine(shell *ishell.Shell, line string) error {
+	args, err := shlex.Split(line)
+	if err != nil {
+		return &utils.ParseError{Err: err}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	cmd := findCmd(args[0])
+	if cmd == nil {
+		return utils.NewParseError("unknown command: %s", args[0])
+	}
+	ic := &ishell.Context{
+		Args:    args[1:],
+		RawArgs: strings.Fields(line),
+		Actions: shell.Actions,
+	}
+	client.EnsureConnected()
+	return utils.RunWithOutputOptions(ic, func() {
+		cmd.Handler()(context.WithValue(context.TODO(), "ishell", ic))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/c4pt0r/tcli"
 	"github.com/c4pt0r/tcli/client"
@@ -16,15 +17,29 @@ import (
 	"github.com/abiosoft/ishell"
 	"github.com/c4pt0r/log"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	plog "github.com/pingcap/log"
+	"go.uber.org/zap"
 )
 
 var (
 	pdAddr         = flag.String("pd", "localhost:2379", "PD addr")
+	standbyPDAddr  = flag.String("standby-pd", "", "standby cluster PD addrs separated by comma, used read-only when the primary is unreachable")
+	readReplicas   = flag.String("read-replicas", "", `clusters holding the same data as the primary, formatted as "name=pd1,pd2;name2=pd3", scans read them following sys.read_policy`)
 	clientLog      = flag.String("log-file", "/dev/null", "TiKV client log file")
 	clientLogLevel = flag.String("log-level", "info", "TiKV client log level")
-	clientmode     = flag.String("mode", "txn", "TiKV API mode, accepted values: [raw | txn]")
-	resultFmt      = flag.String("output-format", "table", "output format, accepted values: [table | json]")
+	clientLogFmt   = flag.String("log-format", "text", "log file format, accepted values: [text | json]")
+	slowThreshold  = flag.Duration("slow-threshold", time.Second, "commands slower than this are logged as slow to the log file, 0 to disable")
+	clientmode     = flag.String("mode", "txn", "TiKV API mode, accepted values: [raw | txn | bolt | badger | etcd | leveldb | redis | offline]")
+	etcdAddrs      = flag.String("etcd", "localhost:2379", "etcd endpoints separated by comma, used by etcd mode")
+	dbPath         = flag.String("path", "", "local database file or directory, used by bolt, badger and leveldb mode")
+	boltBucket     = flag.String("bucket", client.DefaultBoltBucket, "bucket name, used by bolt mode")
+	redisAddr      = flag.String("redis", "localhost:6379", "redis server addr, used by redis mode")
+	offline        = flag.Bool("offline", false, "run without a cluster, kv pairs are kept in memory")
+	offlineData    = flag.String("load", "", "backup csv file to preload in offline mode")
+	resultFmt      = flag.String("output-format", "table", "output format, accepted values: [table | json | raw | markdown | html]")
+	promptTmpl     = flag.String("prompt", "", "prompt template, e.g. \"{cluster}{ro}{snapshot}> \", sets sys.prompt")
+	failFast       = flag.Bool("fail-fast", false, "stop at the first failed command when commands are read from a pipe or a file")
 )
 var (
 	logo string = ""
@@ -35,33 +50,72 @@ var (
 var RegisteredCmds = []tcli.Cmd{
 	kvcmds.ScanCmd{},
 	kvcmds.ScanPrefixCmd{},
+	kvcmds.BrowseCmd{},
 	kvcmds.HeadCmd{},
+	kvcmds.SeekCmd{},
+	kvcmds.NextCmd{},
+	kvcmds.PrevCmd{},
 	kvcmds.PutCmd{},
+	kvcmds.PutsCmd{},
 	kvcmds.BackupCmd{},
+	kvcmds.ManifestCmd{},
+	kvcmds.JobsCmd{},
+	kvcmds.JobCmd{},
 	kvcmds.NewBenchCmd(
 		kvcmds.NewYcsbBench(*pdAddr),
 	),
 	kvcmds.GetCmd{},
+	kvcmds.PrettyPrintCmd{},
+	kvcmds.DescribeCmd{},
+	kvcmds.GetManyCmd{},
+	kvcmds.ExistsCmd{},
+	kvcmds.TTLCmd{},
 	kvcmds.LoadCsvCmd{},
 	kvcmds.DeleteCmd{},
 	kvcmds.DeletePrefixCmd{},
 	kvcmds.DeleteAllCmd{},
 	kvcmds.CountCmd{},
+	kvcmds.EstimateCmd{},
+	kvcmds.VerifyCmd{},
+	kvcmds.DiffCmd{},
+	kvcmds.BeginCmd{},
+	kvcmds.EndCmd{},
+	kvcmds.HistogramCmd{},
+	kvcmds.AnalyzeCmd{},
+	kvcmds.DupesCmd{},
+	kvcmds.SampleCmd{},
+	kvcmds.WatchCmd{},
 	kvcmds.EchoCmd{},
 	kvcmds.HexCmd{},
+	kvcmds.CodecCmd{},
 	kvcmds.VarCmd{},
 	kvcmds.PrintVarsCmd{},
 	kvcmds.PrintSysVarsCmd{},
 	kvcmds.SysVarCmd{},
+	kvcmds.SaveQueryCmd{},
+	kvcmds.RunQueryCmd{},
 	opcmds.ListStoresCmd{},
 	opcmds.ListPDCmd{},
+	opcmds.PingCmd{},
+	opcmds.SessionCmd{},
+	opcmds.GCCmd{},
+	opcmds.UnsafeCmd{},
 	//opcmds.ConnectCmd{},
 	//opcmds.ConfigEditorCmd{},
 }
 
+// checkLogFormat rejects log formats pingcap's logger would panic on
+func checkLogFormat() error {
+	switch *clientLogFmt {
+	case "text", "json":
+		return nil
+	}
+	return utils.NewParseError("invalid -log-format: %s, accepted values: [text | json]", *clientLogFmt)
+}
+
 func initLog() {
 	// keep pingcap's log silent
-	conf := &plog.Config{Level: *clientLogLevel, File: plog.FileLogConfig{Filename: *clientLog}}
+	conf := &plog.Config{Level: *clientLogLevel, Format: *clientLogFmt, File: plog.FileLogConfig{Filename: *clientLog}}
 	lg, r, _ := plog.InitLogger(conf)
 	plog.ReplaceGlobals(lg, r)
 
@@ -76,7 +130,7 @@ func showWelcomeMessage() {
 		client.GetTiKVClient().GetClientMode(),
 	)
 
-	if client.GetTiKVClient().GetClientMode() == client.RAW_CLIENT {
+	if client.GetTiKVClient().GetClientMode() != client.TXN_CLIENT {
 		return
 	}
 
@@ -98,31 +152,158 @@ func showWelcomeMessage() {
 	}
 }
 
+// logCommand writes the command to the log file: every command at debug
+// level, failed and slow ones as warnings
+func logCommand(rawArgs []string, elapsed time.Duration, err error) {
+	fields := []zap.Field{
+		zap.String("cmd", strings.Join(rawArgs, " ")),
+		zap.Duration("elapsed", elapsed),
+	}
+	switch {
+	case err != nil:
+		plog.Warn("command failed", append(fields, zap.Error(err), zap.Int("exit-code", utils.ExitCodeOf(err)))...)
+	case *slowThreshold > 0 && elapsed >= *slowThreshold:
+		plog.Warn("slow command", fields...)
+	default:
+		plog.Debug("command", fields...)
+	}
+}
+
+// exitOnInitError exits with ExitConnError if the store can't be opened
+func exitOnInitError(err error) {
+	fmt.Fprintln(os.Stderr)
+	log.E(err)
+	code := utils.ExitCodeOf(err)
+	if code == utils.ExitCmdError {
+		code = utils.ExitConnError
+	}
+	os.Exit(code)
+}
+
+// batchResult tracks command failures when commands are not typed in a
+// terminal, to compute the exit code
+type batchResult struct {
+	cmds     int
+	failed   int
+	firstErr error
+}
+
+func (r *batchResult) record(err error) {
+	r.cmds++
+	if err == nil {
+		return
+	}
+	r.failed++
+	if r.firstErr == nil {
+		r.firstErr = err
+	}
+	if *failFast {
+		os.Exit(utils.ExitCodeOf(err))
+	}
+}
+
+func (r *batchResult) exitCode() int {
+	switch {
+	case r.failed == 0:
+		return utils.ExitOK
+	case r.failed < r.cmds:
+		return utils.ExitPartialFailure
+	}
+	return utils.ExitCodeOf(r.firstErr)
+}
+
 func main() {
 	flag.Parse()
+	if err := checkLogFormat(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(utils.ExitParseError)
+	}
 	initLog()
-	fmt.Fprintf(os.Stderr, "Try connecting to PD: %s...", *pdAddr)
-	if err := client.InitTiKVClient([]string{*pdAddr}, *clientmode); err != nil {
-		log.Fatal(err)
+	if *offline {
+		*clientmode = "offline"
+	}
+	switch strings.ToLower(*clientmode) {
+	case "offline":
+		fmt.Fprintf(os.Stderr, "Try loading offline data: %s...", *offlineData)
+		cnt, err := client.InitMemClient(*offlineData)
+		if err != nil {
+			exitOnInitError(err)
+		}
+		fmt.Fprintf(os.Stderr, "%d records...", cnt)
+	case "bolt":
+		fmt.Fprintf(os.Stderr, "Try opening bolt database: %s...", *dbPath)
+		if err := client.InitBoltClient(*dbPath, *boltBucket); err != nil {
+			exitOnInitError(err)
+		}
+	case "badger":
+		fmt.Fprintf(os.Stderr, "Try opening badger database: %s...", *dbPath)
+		if err := client.InitBadgerClient(*dbPath); err != nil {
+			exitOnInitError(err)
+		}
+	case "leveldb":
+		fmt.Fprintf(os.Stderr, "Try opening leveldb database: %s...", *dbPath)
+		if err := client.InitLevelDBClient(*dbPath); err != nil {
+			exitOnInitError(err)
+		}
+	case "redis":
+		fmt.Fprintf(os.Stderr, "Try connecting to redis: %s...", *redisAddr)
+		if err := client.InitRedisClient(*redisAddr); err != nil {
+			exitOnInitError(err)
+		}
+	case "etcd":
+		fmt.Fprintf(os.Stderr, "Try connecting to etcd: %s...", *etcdAddrs)
+		if err := client.InitEtcdClient(strings.Split(*etcdAddrs, ",")); err != nil {
+			exitOnInitError(err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Try connecting to PD: %s...", *pdAddr)
+		if *standbyPDAddr != "" {
+			if err := client.InitFailoverTiKVClient([]string{*pdAddr}, strings.Split(*standbyPDAddr, ","), *clientmode); err != nil {
+				exitOnInitError(err)
+			}
+		} else if err := client.InitTiKVClient([]string{*pdAddr}, *clientmode); err != nil {
+			exitOnInitError(err)
+		}
+	}
+	if *readReplicas != "" {
+		if err := client.InitReadReplicas(*readReplicas, *clientmode); err != nil {
+			exitOnInitError(err)
+		}
 	}
 	fmt.Fprintf(os.Stderr, "done\n")
 	utils.InitBuiltinVaribles()
 
 	// Set output format
 	utils.SysVarSet(utils.SysVarPrintFormatKey, *resultFmt)
+	utils.SysVarSet(utils.SysVarPromptKey, *promptTmpl)
+
+	if flag.Arg(0) == "serve" {
+		runServer(flag.Args()[1:])
+		return
+	}
 
 	showWelcomeMessage()
 
 	// set shell prompts
 	shell := ishell.New()
-	if client.GetTiKVClient().GetClientMode() == client.RAW_CLIENT {
-		// TODO: add pd leader addr after we can get PD client from RawKV client.
-		shell.SetPrompt(fmt.Sprintf("%s> ", client.GetTiKVClient().GetClientMode()))
-	} else {
-		pdLeaderAddr := client.GetTiKVClient().GetPDClient().GetLeaderAddr()
-		shell.SetPrompt(fmt.Sprintf("%s @ %s> ", client.GetTiKVClient().GetClientMode(), pdLeaderAddr))
-	}
+	refreshPrompt(shell)
+	// the keepalive probe marks a slow or lost connection in the prompt
+	client.OnHealthChange(func(client.Health) { refreshPrompt(shell) })
+	client.StartKeepalive()
 	shell.EOF(func(c *ishell.Context) { shell.Close() })
+	batch := !isatty.IsTerminal(os.Stdin.Fd())
+	result := &batchResult{}
+	shell.NotFound(func(c *ishell.Context) {
+		utils.OutputWithElapse(func() error {
+			return utils.NewParseError("unknown command: %s", strings.Join(c.RawArgs, " "))
+		})
+		if batch {
+			result.record(utils.TakeLastCmdError())
+		}
+	})
+	utils.SetCmdLineRunner(func(line string) error {
+		return runCmdLine(shell, line)
+	})
 	shell.AutoHelp(false)
 
 	// register shell commands
@@ -149,10 +330,26 @@ func main() {
 					c.Println(longhelp)
 					return
 				}
-				handler(ctx)
+				client.EnsureConnected()
+				utils.TakeLastCmdError()
+				start := time.Now()
+				if err := utils.RunWithOutputOptions(c, func() { handler(ctx) }); err != nil {
+					utils.OutputWithElapse(func() error { return err })
+				}
+				err := utils.TakeLastCmdError()
+				elapsed := time.Since(start)
+				logCommand(c.RawArgs, elapsed, err)
+				setLastElapsed(elapsed)
+				refreshPrompt(shell)
+				if batch {
+					result.record(err)
+				}
 			},
 		})
 	}
 	shell.Run()
 	shell.Close()
+	if batch {
+		os.Exit(result.exitCode())
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/abiosoft/ishell"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// The prompt follows the sys.prompt template when it's set, e.g.
+//
+//	sysvar sys.prompt="{cluster}{ro} {elapsed}> "
+//
+// markers that don't apply, like {snapshot} without a pinned snapshot,
+// expand to nothing.
+
+// the elapsed time of the last command, in nanoseconds
+var lastElapsed int64
+
+func setLastElapsed(d time.Duration) {
+	atomic.StoreInt64(&lastElapsed, int64(d))
+}
+
+// promptFields returns the values of the prompt template markers
+func promptFields() []string {
+	kvc := client.GetTiKVClient()
+	mode := strings.ToLower(strings.TrimPrefix(kvc.GetClientMode().String(), "Mode: "))
+	pd := ""
+	if kvc.GetClientMode() == client.TXN_CLIENT {
+		pd = kvc.GetPDClient().GetLeaderAddr()
+	}
+	snapshot := ""
+	if ts := client.PinnedSnapshotTS(); ts != 0 {
+		snapshot = fmt.Sprintf(" [snapshot %d]", ts)
+	}
+	ro := ""
+	if client.OnStandby() {
+		ro = " [read-only]"
+	}
+	health := ""
+	if h := client.CurrentHealth(); h != client.HealthOK {
+		health = fmt.Sprintf("[%s] ", h)
+	}
+	elapsed := ""
+	if d := time.Duration(atomic.LoadInt64(&lastElapsed)); d > 0 {
+		elapsed = d.Round(time.Millisecond).String()
+	}
+	return []string{
+		"{mode}", mode,
+		"{pd}", pd,
+		"{cluster}", kvc.GetClusterID(),
+		"{snapshot}", snapshot,
+		"{ro}", ro,
+		"{health}", health,
+		"{elapsed}", elapsed,
+	}
+}
+
+// renderPrompt expands sys.prompt, or builds the default prompt
+func renderPrompt() string {
+	if tmpl, _ := utils.SysVarGet(utils.SysVarPromptKey); tmpl != "" {
+		return strings.NewReplacer(promptFields()...).Replace(tmpl)
+	}
+	kvc := client.GetTiKVClient()
+	// TODO: add pd leader addr after we can get PD client from RawKV client.
+	prompt := fmt.Sprintf("%s> ", kvc.GetClientMode())
+	if kvc.GetClientMode() == client.TXN_CLIENT {
+		prompt = fmt.Sprintf("%s @ %s> ", kvc.GetClientMode(), kvc.GetPDClient().GetLeaderAddr())
+	}
+	if h := client.CurrentHealth(); h != client.HealthOK {
+		prompt = fmt.Sprintf("[%s] %s", h, prompt)
+	}
+	return prompt
+}
+
+func refreshPrompt(shell *ishell.Shell) {
+	shell.SetPrompt(renderPrompt())
+}
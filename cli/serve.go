@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli/server"
+)
+
+// runServer handles `tcli [flags] serve [serve flags]`
+func runServer(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	httpAddr := fs.String("http", "127.0.0.1:8080", "HTTP API listen addr")
+	grpcAddr := fs.String("grpc", "", "gRPC API listen addr, e.g. 127.0.0.1:8081, disabled if empty")
+	token := fs.String("token", "", "bearer token granting full access, required unless -read-only is set")
+	readOnlyToken := fs.String("read-only-token", "", "bearer token granting read access only")
+	readOnly := fs.Bool("read-only", false, "reject all writes")
+	jobsFile := fs.String("jobs", "", "JSON file of scheduled jobs")
+	fs.Parse(args)
+	// writes are never open to anyone who can reach the port
+	if *token == "" && *readOnlyToken == "" && !*readOnly {
+		log.Fatal("serve needs -token, or -read-only for an unauthenticated read-only API")
+	}
+
+	if *jobsFile != "" {
+		jobs, err := server.LoadJobs(*jobsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.RunJobs(context.Background(), jobs)
+	}
+
+	s := server.NewServer(server.Config{
+		Token:         *token,
+		ReadOnlyToken: *readOnlyToken,
+		ReadOnly:      *readOnly,
+	})
+	if *grpcAddr != "" {
+		go func() {
+			if err := s.ListenAndServeGRPC(*grpcAddr); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	if err := s.ListenAndServe(*httpAddr); err != nil {
+		log.Fatal(err)
+	}
+}
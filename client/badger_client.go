@@ -0,0 +1,242 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/dgraph-io/badger/v3"
+	pd "github.com/tikv/pd/client"
+)
+
+func newBadgerClient(path string) (*badgerClient, error) {
+	db, err := badger.Open(badger.DefaultOptions(path).WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &badgerClient{
+		db:   db,
+		path: path,
+	}, nil
+}
+
+type badgerClient struct {
+	db   *badger.DB
+	path string
+}
+
+func (c *badgerClient) Close() {
+	if c.db != nil {
+		c.db.Close()
+	}
+}
+
+func (c *badgerClient) GetClientMode() TiKV_MODE {
+	return BADGER_CLIENT
+}
+
+func (c *badgerClient) GetClusterID() string {
+	return c.path
+}
+
+func (c *badgerClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("badgerClient does not support GetStores()")
+}
+
+func (c *badgerClient) GetPDs() ([]PDInfo, error) {
+	return nil, errors.New("badgerClient does not support GetPDs()")
+}
+
+func (c *badgerClient) GetPDClient() pd.Client {
+	panic("badgerClient does not support GetPDClient()")
+}
+
+func (c *badgerClient) Put(ctx context.Context, kv KV) error {
+	return c.BatchPut(ctx, []KV{kv})
+}
+
+func (c *badgerClient) BatchPut(ctx context.Context, kvs []KV) error {
+	wb := c.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, kv := range kvs {
+		if err := wb.Set(kv.K, kv.V); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (c *badgerClient) Get(ctx context.Context, k Key) (KV, error) {
+	var ret KV
+	err := c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(k)
+		if err == badger.ErrKeyNotFound {
+			return errors.New("key not found")
+		}
+		if err != nil {
+			return err
+		}
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		ret = KV{K: k, V: v}
+		return nil
+	})
+	return ret, err
+}
+
+func (c *badgerClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	var ret KVS
+	err := c.db.View(func(txn *badger.Txn) error {
+		for _, k := range keys {
+			item, err := txn.Get(k)
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			v, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, KV{K: k, V: v})
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// GetTTL returns the remaining time to live of a key, 0 means the key never expires
+func (c *badgerClient) GetTTL(ctx context.Context, k Key) (time.Duration, error) {
+	var ttl time.Duration
+	err := c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(k)
+		if err == badger.ErrKeyNotFound {
+			return errors.New("key not found")
+		}
+		if err != nil {
+			return err
+		}
+		if expiresAt := item.ExpiresAt(); expiresAt > 0 {
+			ttl = time.Until(time.Unix(int64(expiresAt), 0))
+		}
+		return nil
+	})
+	return ttl, err
+}
+
+func (c *badgerClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ret []KV
+	var lastKey KV
+	count := 0
+	err = c.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		// key-only scans never touch the value log
+		iterOpts.PrefetchValues = !(keyOnly || countOnly)
+		iterOpts.Reverse = reverse
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		// a reverse seek lands on the last key <= upper, an empty one on the
+		// last key
+		seekKey := lower
+		if reverse {
+			seekKey = upper
+		}
+		for it.Seek(seekKey); it.Valid(); it.Next() {
+			if !countOnly && limit == 0 {
+				break
+			}
+			item := it.Item()
+			k := item.KeyCopy(nil)
+			if reverse && upper != nil && bytes.Equal(k, upper) {
+				continue
+			}
+			if afterScanRange(k, lower, upper, reverse) {
+				break
+			}
+			// count only will not use limit
+			if !countOnly {
+				var v []byte
+				if !keyOnly {
+					var err error
+					if v, err = item.ValueCopy(nil); err != nil {
+						return err
+					}
+				}
+				ret = append(ret, KV{K: k, V: v})
+				limit--
+			}
+			count++
+			lastKey.K = k
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if countOnly {
+		ret = append(ret, KV{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))})
+		ret = append(ret, KV{K: []byte("Last Key"), V: []byte(lastKey.K)})
+	}
+	return ret, count, nil
+}
+
+func (c *badgerClient) Delete(ctx context.Context, k Key) error {
+	return c.BatchDelete(ctx, []KV{{K: k}})
+}
+
+func (c *badgerClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	wb := c.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, kv := range kvs {
+		if err := wb.Delete(kv.K); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// return lastKey, delete count, error
+func (c *badgerClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	var batch []KV
+	var lastKey Key
+	err := c.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.PrefetchValues = false
+		iterOpts.Prefix = prefix
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		for it.Seek(prefix); it.Valid() && limit > 0; it.Next() {
+			lastKey = it.Item().KeyCopy(nil)
+			batch = append(batch, KV{K: lastKey})
+			limit--
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := c.BatchDelete(ctx, batch); err != nil {
+		return nil, 0, err
+	}
+	return lastKey, len(batch), nil
+}
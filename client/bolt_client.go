@@ -0,0 +1,231 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	pd "github.com/tikv/pd/client"
+	bolt "go.etcd.io/bbolt"
+)
+
+var DefaultBoltBucket = "tcli"
+
+func newBoltClient(path string, bucket string) (*boltClient, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		bucket = DefaultBoltBucket
+	}
+	return &boltClient{
+		db:     db,
+		path:   path,
+		bucket: []byte(bucket),
+	}, nil
+}
+
+// boltClient runs tcli commands against a local bbolt file,
+// all keys live in a single (configurable) bucket.
+type boltClient struct {
+	db     *bolt.DB
+	path   string
+	bucket []byte
+}
+
+func (c *boltClient) Close() {
+	if c.db != nil {
+		c.db.Close()
+	}
+}
+
+func (c *boltClient) GetClientMode() TiKV_MODE {
+	return BOLT_CLIENT
+}
+
+func (c *boltClient) GetClusterID() string {
+	return fmt.Sprintf("%s:%s", c.path, c.bucket)
+}
+
+func (c *boltClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("boltClient does not support GetStores()")
+}
+
+func (c *boltClient) GetPDs() ([]PDInfo, error) {
+	return nil, errors.New("boltClient does not support GetPDs()")
+}
+
+func (c *boltClient) GetPDClient() pd.Client {
+	panic("boltClient does not support GetPDClient()")
+}
+
+func (c *boltClient) Put(ctx context.Context, kv KV) error {
+	return c.BatchPut(ctx, []KV{kv})
+}
+
+func (c *boltClient) BatchPut(ctx context.Context, kvs []KV) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(c.bucket)
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			if err := b.Put(kv.K, kv.V); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *boltClient) Get(ctx context.Context, k Key) (KV, error) {
+	var ret KV
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if b == nil {
+			return errors.New("key not found")
+		}
+		v := b.Get(k)
+		if v == nil {
+			return errors.New("key not found")
+		}
+		// values returned by bolt are only valid inside the transaction
+		ret = KV{K: k, V: append([]byte{}, v...)}
+		return nil
+	})
+	return ret, err
+}
+
+func (c *boltClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	var ret KVS
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if b == nil {
+			return nil
+		}
+		for _, k := range keys {
+			if v := b.Get(k); v != nil {
+				ret = append(ret, KV{K: k, V: append([]byte{}, v...)})
+			}
+		}
+		return nil
+	})
+	return ret, err
+}
+
+func (c *boltClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ret []KV
+	var lastKey KV
+	count := 0
+	err = c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if b == nil {
+			return nil
+		}
+		cur := b.Cursor()
+		first, next := func() ([]byte, []byte) { return cur.Seek(lower) }, cur.Next
+		if reverse {
+			first, next = func() ([]byte, []byte) {
+				if upper == nil {
+					return cur.Last()
+				}
+				// Seek lands on the first key >= upper
+				if k, _ := cur.Seek(upper); k == nil {
+					return cur.Last()
+				}
+				return cur.Prev()
+			}, cur.Prev
+		}
+		for k, v := first(); k != nil; k, v = next() {
+			if !countOnly && limit == 0 {
+				break
+			}
+			if afterScanRange(k, lower, upper, reverse) {
+				break
+			}
+			// count only will not use limit
+			if !countOnly {
+				if keyOnly {
+					ret = append(ret, KV{K: append([]byte{}, k...), V: nil})
+				} else {
+					ret = append(ret, KV{K: append([]byte{}, k...), V: append([]byte{}, v...)})
+				}
+				limit--
+			}
+			count++
+			lastKey.K = append([]byte{}, k...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if countOnly {
+		ret = append(ret, KV{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))})
+		ret = append(ret, KV{K: []byte("Last Key"), V: []byte(lastKey.K)})
+	}
+	return ret, count, nil
+}
+
+func (c *boltClient) Delete(ctx context.Context, k Key) error {
+	return c.BatchDelete(ctx, []KV{{K: k}})
+}
+
+func (c *boltClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if b == nil {
+			return nil
+		}
+		for _, kv := range kvs {
+			if err := b.Delete(kv.K); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// return lastKey, delete count, error
+func (c *boltClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	var batch []KV
+	var lastKey Key
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if b == nil {
+			return nil
+		}
+		cur := b.Cursor()
+		for k, _ := cur.Seek(prefix); k != nil && limit > 0; k, _ = cur.Next() {
+			if !bytes.HasPrefix(k, prefix) {
+				break
+			}
+			lastKey = append([]byte{}, k...)
+			batch = append(batch, KV{K: lastKey})
+			limit--
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := c.BatchDelete(ctx, batch); err != nil {
+		return nil, 0, err
+	}
+	return lastKey, len(batch), nil
+}
@@ -7,10 +7,10 @@ import (
 	"os"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/c4pt0r/tcli/utils"
 
-	"github.com/pkg/errors"
 	pd "github.com/tikv/pd/client"
 )
 
@@ -24,7 +24,19 @@ type KV struct {
 
 type KVS []KV
 
+// truncate keeps the pairs fitting sys.max_result_rows and
+// sys.max_result_bytes, and returns the variable of the cap that was hit
+func (kvs KVS) truncate() (KVS, string) {
+	n, by := utils.ResultQuota(len(kvs), func(i int) int { return len(kvs[i].K) + len(kvs[i].V) })
+	return kvs[:n], by
+}
+
 func (kvs KVS) Print() {
+	all := kvs
+	kvs, by := kvs.truncate()
+	if len(kvs) < len(all) {
+		defer utils.PrintTruncatedFooter(len(kvs), len(all), by)
+	}
 
 	formatter := "table"
 	if r, ok := utils.SysVarGet(utils.SysVarPrintFormatKey); ok {
@@ -49,7 +61,7 @@ func (kvs KVS) Print() {
 				fmt.Println(kv.K, "\t=>\t", kv.V)
 			}
 		}
-	default: // table
+	default: // table, markdown, html
 		{
 			if len(kvs) == 0 {
 				return
@@ -57,39 +69,199 @@ func (kvs KVS) Print() {
 			data := [][]string{
 				{"Key", "Value"},
 			}
+			// JSON values follow sys.json_render, colors only in text tables
+			mode := utils.JSONRenderMode()
+			colored := formatter != "markdown" && formatter != "html"
+			preformatted := false
+			// column statistics are about the values, not their rendering
+			rawData := [][]string{data[0]}
 			for _, kv := range kvs {
 				row := []string{string(kv.K), string(kv.V)}
+				rawData = append(rawData, row)
+				if v, ok := utils.RenderJSONValue(kv.V, mode, colored); ok {
+					row = []string{row[0], v}
+					preformatted = true
+				}
 				data = append(data, row)
 			}
-			utils.PrintTable(data)
-			if len(kvs) > 1 {
-				fmt.Fprintf(os.Stderr, "%d Records Found\n", len(kvs))
+			if preformatted {
+				utils.PrintPreformattedTable(data)
+			} else {
+				utils.PrintTable(data)
+			}
+			if utils.ColumnStatsEnabled() {
+				utils.PrintColumnStats(rawData)
+			}
+			if len(all) > 1 {
+				fmt.Fprintf(os.Stderr, "%d Records Found\n", len(all))
 			} else {
-				fmt.Fprintf(os.Stderr, "%d Record Found\n", len(kvs))
+				fmt.Fprintf(os.Stderr, "%d Record Found\n", len(all))
 			}
 		}
 
 	}
 }
 
+// PrintFlattened prints a table with a value.<field> column for every
+// top-level field of the JSON object values, in order of appearance. Values
+// that are not JSON objects go to the Value column.
+func (kvs KVS) PrintFlattened() {
+	if len(kvs) == 0 {
+		return
+	}
+	all := kvs
+	kvs, by := kvs.truncate()
+	if len(kvs) < len(all) {
+		defer utils.PrintTruncatedFooter(len(kvs), len(all), by)
+	}
+	var fields []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]string, len(kvs))
+	plain := false
+	for i, kv := range kvs {
+		names, values, ok := utils.FlattenJSONObject(kv.V)
+		if !ok {
+			plain = true
+			continue
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				fields = append(fields, name)
+			}
+		}
+		rows[i] = values
+	}
+	header := []string{"Key"}
+	for _, name := range fields {
+		header = append(header, "value."+name)
+	}
+	if plain {
+		header = append(header, "Value")
+	}
+	data := [][]string{header}
+	for i, kv := range kvs {
+		row := []string{string(kv.K)}
+		for _, name := range fields {
+			// missing fields are left empty
+			row = append(row, rows[i][name])
+		}
+		if plain {
+			v := ""
+			if rows[i] == nil {
+				v = string(kv.V)
+			}
+			row = append(row, v)
+		}
+		data = append(data, row)
+	}
+	utils.PrintTable(data)
+	if utils.ColumnStatsEnabled() {
+		utils.PrintColumnStats(data)
+	}
+	if len(all) > 1 {
+		fmt.Fprintf(os.Stderr, "%d Records Found\n", len(all))
+	} else {
+		fmt.Fprintf(os.Stderr, "%d Record Found\n", len(all))
+	}
+}
+
 // Global client instance, safe to use concurrently
 var (
 	_globalKvClient atomic.Value
 )
 
 func InitTiKVClient(pdAddrs []string, clientMode string) error {
-	switch strings.ToLower(clientMode) {
-	case "raw":
-		kvClient := newRawKVClient(pdAddrs)
-		_globalKvClient.Store(kvClient)
-		return nil
-	case "txn":
-		kvClient := newTxnKVClient(pdAddrs)
-		_globalKvClient.Store(kvClient)
-		return nil
-	default:
-		return errors.Errorf("Unrecognized TiKV mode: %s", clientMode)
+	kvClient, err := tryNewTiKVClient(pdAddrs, clientMode)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(kvClient)
+	setReconnect(func() (Client, error) { return tryNewTiKVClient(pdAddrs, clientMode) })
+	return nil
+}
+
+// InitFailoverTiKVClient connects to the primary cluster, and switches to the
+// standby cluster (read-only) once the primary becomes unreachable
+func InitFailoverTiKVClient(pdAddrs []string, standbyPDAddrs []string, clientMode string) error {
+	primary, err := tryNewTiKVClient(pdAddrs, clientMode)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(newFailoverClient(primary, standbyPDAddrs, clientMode))
+	setReconnect(func() (Client, error) {
+		primary, err := tryNewTiKVClient(pdAddrs, clientMode)
+		if err != nil {
+			return nil, err
+		}
+		return newFailoverClient(primary, standbyPDAddrs, clientMode), nil
+	})
+	return nil
+}
+
+// InitBadgerClient opens a local badger directory as the global client
+func InitBadgerClient(path string) error {
+	kvClient, err := newBadgerClient(path)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(kvClient)
+	return nil
+}
+
+// InitEtcdClient connects to an etcd v3 cluster as the global client
+func InitEtcdClient(endpoints []string) error {
+	kvClient, err := newEtcdClient(endpoints)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(kvClient)
+	return nil
+}
+
+// InitMemClient creates an in-memory global client for offline mode,
+// preloaded with the given backup csv file if any
+func InitMemClient(csvFile string) (int, error) {
+	kvClient := newMemClient()
+	var cnt int
+	if csvFile != "" {
+		var err error
+		if cnt, err = kvClient.LoadCsv(csvFile); err != nil {
+			return 0, err
+		}
+	}
+	_globalKvClient.Store(kvClient)
+	return cnt, nil
+}
+
+// InitLevelDBClient opens a local leveldb directory as the global client
+func InitLevelDBClient(path string) error {
+	kvClient, err := newLevelDBClient(path)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(kvClient)
+	return nil
+}
+
+// InitRedisClient connects to a redis server as the global client
+func InitRedisClient(addr string) error {
+	kvClient, err := newRedisClient(addr)
+	if err != nil {
+		return err
+	}
+	_globalKvClient.Store(kvClient)
+	return nil
+}
+
+// InitBoltClient opens a local bbolt file as the global client
+func InitBoltClient(path string, bucket string) error {
+	kvClient, err := newBoltClient(path, bucket)
+	if err != nil {
+		return err
 	}
+	_globalKvClient.Store(kvClient)
+	return nil
 }
 
 func GetTiKVClient() Client {
@@ -99,6 +271,15 @@ func GetTiKVClient() Client {
 // Make sure txnkvClient implements Client interface
 var _ Client = (*txnkvClient)(nil)
 var _ Client = (*rawkvClient)(nil)
+var _ Client = (*boltClient)(nil)
+var _ Client = (*badgerClient)(nil)
+var _ TTLClient = (*badgerClient)(nil)
+var _ Client = (*etcdClient)(nil)
+var _ WatchClient = (*etcdClient)(nil)
+var _ Client = (*memClient)(nil)
+var _ Client = (*leveldbClient)(nil)
+var _ Client = (*redisClient)(nil)
+var _ Client = (*failoverClient)(nil)
 
 type Client interface {
 	GetClientMode() TiKV_MODE
@@ -111,6 +292,8 @@ type Client interface {
 	BatchPut(ctx context.Context, kv []KV) error
 
 	Get(ctx context.Context, k Key) (KV, error)
+	// BatchGet returns the keys found, in the order of keys
+	BatchGet(ctx context.Context, keys []Key) (KVS, error)
 	Scan(ctx context.Context, prefix []byte) (KVS, int, error)
 
 	Delete(ctx context.Context, k Key) error
@@ -118,11 +301,22 @@ type Client interface {
 	DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error)
 }
 
+// TTLClient is implemented by clients whose storage supports key expiration
+type TTLClient interface {
+	GetTTL(ctx context.Context, k Key) (time.Duration, error)
+}
+
 type TiKV_MODE int
 
 const (
-	RAW_CLIENT TiKV_MODE = 0
-	TXN_CLIENT TiKV_MODE = 1
+	RAW_CLIENT     TiKV_MODE = 0
+	TXN_CLIENT     TiKV_MODE = 1
+	BOLT_CLIENT    TiKV_MODE = 2
+	BADGER_CLIENT  TiKV_MODE = 3
+	ETCD_CLIENT    TiKV_MODE = 4
+	MEM_CLIENT     TiKV_MODE = 5
+	LEVELDB_CLIENT TiKV_MODE = 6
+	REDIS_CLIENT   TiKV_MODE = 7
 )
 
 func (mode TiKV_MODE) String() string {
@@ -131,6 +325,18 @@ func (mode TiKV_MODE) String() string {
 		return "Mode: Raw"
 	case TXN_CLIENT:
 		return "Mode: Txn"
+	case BOLT_CLIENT:
+		return "Mode: Bolt"
+	case BADGER_CLIENT:
+		return "Mode: Badger"
+	case ETCD_CLIENT:
+		return "Mode: Etcd"
+	case MEM_CLIENT:
+		return "Mode: Offline"
+	case LEVELDB_CLIENT:
+		return "Mode: LevelDB"
+	case REDIS_CLIENT:
+		return "Mode: Redis"
 	}
 	return "unknown"
 }
@@ -145,7 +351,9 @@ type StoreInfo struct {
 	Addr          string
 	State         string
 	StatusAddress string
-	Labels        string
+	// the value of the zone label, PD's usual location label
+	Zone   string
+	Labels string
 }
 
 type PDInfo struct {
@@ -154,16 +362,16 @@ type PDInfo struct {
 }
 
 func (StoreInfo) TableTitle() []string {
-	return []string{"Store ID", "Version", "Address", "State", "Status Address", "Labels"}
+	return []string{"Store ID", "Version", "Address", "State", "Status Address", "Zone", "Labels"}
 }
 
 func (s StoreInfo) Flatten() []string {
-	return []string{s.ID, s.Version, s.Addr, s.State, s.StatusAddress, s.Labels}
+	return []string{s.ID, s.Version, s.Addr, s.State, s.StatusAddress, s.Zone, s.Labels}
 }
 
 func (s StoreInfo) String() string {
-	return fmt.Sprintf("store_id:\"%s\" version:\"%s\" addr:\"%s\" state:\"%s\" status_addr:\"%s\" labels:\"%s\"",
-		s.ID, s.Version, s.Addr, s.State, s.StatusAddress, s.Labels)
+	return fmt.Sprintf("store_id:\"%s\" version:\"%s\" addr:\"%s\" state:\"%s\" status_addr:\"%s\" zone:\"%s\" labels:\"%s\"",
+		s.ID, s.Version, s.Addr, s.State, s.StatusAddress, s.Zone, s.Labels)
 }
 
 func (p PDInfo) TableTitle() []string {
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/tikv/client-go/v2/tikvrpc"
+	pd "github.com/tikv/pd/client"
+)
+
+// UnsafeDestroyRangeClient is implemented by clients of a transactional
+// TiKV cluster
+type UnsafeDestroyRangeClient interface {
+	// UnsafeDestroyRange deletes all data of [startKey, endKey) from every
+	// TiKV store, bypassing MVCC, transactions and raft
+	UnsafeDestroyRange(ctx context.Context, startKey, endKey []byte) error
+}
+
+// TiDB's GC worker waits as long for each store
+const unsafeDestroyRangeTimeout = 5 * time.Minute
+
+func (c *txnkvClient) UnsafeDestroyRange(ctx context.Context, startKey, endKey []byte) error {
+	stores, err := c.txnClient.GetPDClient().GetAllStores(ctx, pd.WithExcludeTombstone())
+	if err != nil {
+		return err
+	}
+	req := tikvrpc.NewRequest(tikvrpc.CmdUnsafeDestroyRange, &kvrpcpb.UnsafeDestroyRangeRequest{
+		StartKey: startKey,
+		EndKey:   endKey,
+	})
+	var errs []string
+	for _, store := range stores {
+		// TiFlash replicas are cleaned by TiFlash itself
+		isTiFlash := false
+		for _, label := range store.GetLabels() {
+			if label.Key == "engine" && label.Value == "tiflash" {
+				isTiFlash = true
+			}
+		}
+		if isTiFlash {
+			continue
+		}
+		resp, err := c.txnClient.GetTiKVClient().SendRequest(ctx, store.GetAddress(), req, unsafeDestroyRangeTimeout)
+		if err == nil {
+			if resp.Resp == nil {
+				err = errors.New("empty response")
+			} else if msg := resp.Resp.(*kvrpcpb.UnsafeDestroyRangeResponse).GetError(); msg != "" {
+				err = errors.New(msg)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("store %d (%s): %v", store.GetId(), store.GetAddress(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("unsafe destroy range failed on %d stores: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *failoverClient) UnsafeDestroyRange(ctx context.Context, startKey, endKey []byte) error {
+	return c.write(func(cli Client) error {
+		dc, ok := cli.(UnsafeDestroyRangeClient)
+		if !ok {
+			return errors.New("unsafe destroy range is only supported in txn mode")
+		}
+		return dc.UnsafeDestroyRange(ctx, startKey, endKey)
+	})
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	pd "github.com/tikv/pd/client"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// etcd rejects transactions with more operations than this by default
+var MaxEtcdTxnOps = 128
+
+func newEtcdClient(endpoints []string) (*etcdClient, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdClient{
+		etcdClient: client,
+		endpoints:  endpoints,
+	}, nil
+}
+
+type etcdClient struct {
+	etcdClient *clientv3.Client
+	endpoints  []string
+}
+
+func (c *etcdClient) Close() {
+	if c.etcdClient != nil {
+		c.etcdClient.Close()
+	}
+}
+
+func (c *etcdClient) GetClientMode() TiKV_MODE {
+	return ETCD_CLIENT
+}
+
+func (c *etcdClient) GetClusterID() string {
+	resp, err := c.etcdClient.MemberList(context.TODO())
+	if err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d", resp.Header.GetClusterId())
+}
+
+func (c *etcdClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("etcdClient does not support GetStores()")
+}
+
+func (c *etcdClient) GetPDs() ([]PDInfo, error) {
+	resp, err := c.etcdClient.MemberList(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	var ret []PDInfo
+	for _, member := range resp.Members {
+		ret = append(ret, PDInfo{Name: member.GetName(), ClientURLs: member.GetClientURLs()})
+	}
+	return ret, nil
+}
+
+func (c *etcdClient) GetPDClient() pd.Client {
+	panic("etcdClient does not support GetPDClient()")
+}
+
+func (c *etcdClient) Put(ctx context.Context, kv KV) error {
+	_, err := c.etcdClient.Put(context.TODO(), string(kv.K), string(kv.V))
+	return err
+}
+
+func (c *etcdClient) BatchPut(ctx context.Context, kvs []KV) error {
+	var ops []clientv3.Op
+	for _, kv := range kvs {
+		ops = append(ops, clientv3.OpPut(string(kv.K), string(kv.V)))
+	}
+	return c.commitOps(ops)
+}
+
+func (c *etcdClient) Get(ctx context.Context, k Key) (KV, error) {
+	resp, err := c.etcdClient.Get(context.TODO(), string(k))
+	if err != nil {
+		return KV{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return KV{}, errors.New("key not found")
+	}
+	return KV{K: k, V: resp.Kvs[0].Value}, nil
+}
+
+func (c *etcdClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	var ret KVS
+	for _, k := range keys {
+		resp, err := c.etcdClient.Get(context.TODO(), string(k))
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Kvs) > 0 {
+			ret = append(ret, KV{K: k, V: resp.Kvs[0].Value})
+		}
+	}
+	return ret, nil
+}
+
+func (c *etcdClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// a range request covers [lower, upper), "\x00" with WithFromKey means
+	// all keys
+	rangeStart := string(lower)
+	if rangeStart == "" {
+		rangeStart = "\x00"
+	}
+	rangeOpt := clientv3.WithFromKey()
+	if upper != nil {
+		rangeOpt = clientv3.WithRange(string(upper))
+	}
+
+	if countOnly {
+		resp, err := c.etcdClient.Get(context.TODO(), rangeStart, rangeOpt, clientv3.WithCountOnly())
+		if err != nil {
+			return nil, 0, err
+		}
+		count := int(resp.Count)
+		var lastKey []byte
+		if count > 0 {
+			resp, err := c.etcdClient.Get(context.TODO(), rangeStart, rangeOpt, clientv3.WithKeysOnly(),
+				clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend), clientv3.WithLimit(1))
+			if err != nil {
+				return nil, 0, err
+			}
+			if len(resp.Kvs) > 0 {
+				lastKey = resp.Kvs[0].Key
+			}
+		}
+		ret := []KV{
+			{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))},
+			{K: []byte("Last Key"), V: lastKey},
+		}
+		return ret, count, nil
+	}
+
+	order := clientv3.SortAscend
+	if reverse {
+		order = clientv3.SortDescend
+	}
+	opts := []clientv3.OpOption{rangeOpt, clientv3.WithLimit(int64(limit)),
+		clientv3.WithSort(clientv3.SortByKey, order)}
+	if keyOnly {
+		opts = append(opts, clientv3.WithKeysOnly())
+	}
+	resp, err := c.etcdClient.Get(context.TODO(), rangeStart, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ret []KV
+	for _, kv := range resp.Kvs {
+		ret = append(ret, KV{K: kv.Key, V: kv.Value})
+	}
+	return ret, len(ret), nil
+}
+
+func (c *etcdClient) Delete(ctx context.Context, k Key) error {
+	_, err := c.etcdClient.Delete(context.TODO(), string(k))
+	return err
+}
+
+func (c *etcdClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	var ops []clientv3.Op
+	for _, kv := range kvs {
+		ops = append(ops, clientv3.OpDelete(string(kv.K)))
+	}
+	return c.commitOps(ops)
+}
+
+// return lastKey, delete count, error
+func (c *etcdClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	resp, err := c.etcdClient.Get(context.TODO(), string(prefix), clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithLimit(int64(limit)), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, 0, nil
+	}
+	var batch []KV
+	for _, kv := range resp.Kvs {
+		batch = append(batch, KV{K: kv.Key})
+	}
+	if err := c.BatchDelete(ctx, batch); err != nil {
+		return nil, 0, err
+	}
+	return batch[len(batch)-1].K, len(batch), nil
+}
+
+// commitOps commits ops in transactions no larger than MaxEtcdTxnOps
+func (c *etcdClient) commitOps(ops []clientv3.Op) error {
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > MaxEtcdTxnOps {
+			n = MaxEtcdTxnOps
+		}
+		if _, err := c.etcdClient.Txn(context.TODO()).Then(ops[:n]...).Commit(); err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+// Watch streams changes under prefix from the etcd watch API
+func (c *etcdClient) Watch(ctx context.Context, prefix Key, cb func(WatchEvent)) error {
+	wch := c.etcdClient.Watch(ctx, string(prefix), clientv3.WithPrefix())
+	for resp := range wch {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range resp.Events {
+			switch {
+			case ev.Type == clientv3.EventTypeDelete:
+				cb(WatchEvent{Type: WatchEventDelete, KV: KV{K: ev.Kv.Key}})
+			case ev.IsCreate():
+				cb(WatchEvent{Type: WatchEventPut, KV: KV{K: ev.Kv.Key, V: ev.Kv.Value}})
+			default:
+				cb(WatchEvent{Type: WatchEventUpdate, KV: KV{K: ev.Kv.Key, V: ev.Kv.Value}})
+			}
+		}
+	}
+	return nil
+}
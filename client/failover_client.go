@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/fatih/color"
+	pd "github.com/tikv/pd/client"
+)
+
+var errStandbyReadOnly = errors.New("connected to the standby cluster, which is read-only")
+
+var errSnapshotOnStandby = errors.New(`switched to the standby cluster, which can't serve the pinned snapshot, use "end" to read the standby`)
+
+func tryNewTiKVClient(pdAddrs []string, clientMode string) (Client, error) {
+	switch strings.ToLower(clientMode) {
+	case "raw":
+		return newRawKVClient(pdAddrs)
+	case "txn":
+		return newTxnKVClient(pdAddrs)
+	}
+	return nil, utils.NewParseError("Unrecognized TiKV mode: %s", clientMode)
+}
+
+// failoverClient sends requests to the primary cluster, once the primary
+// can't be reached it connects to the standby cluster and serves reads from
+// it for the rest of the session, writes are rejected.
+type failoverClient struct {
+	primary      Client
+	standbyAddrs []string
+	clientMode   string
+
+	mu      sync.Mutex
+	standby Client
+}
+
+func newFailoverClient(primary Client, standbyAddrs []string, clientMode string) *failoverClient {
+	return &failoverClient{
+		primary:      primary,
+		standbyAddrs: standbyAddrs,
+		clientMode:   clientMode,
+	}
+}
+
+// Close closes the clients of both clusters
+func (c *failoverClient) Close() {
+	if cl, ok := c.primary.(closer); ok {
+		cl.Close()
+	}
+	if cl, ok := c.onStandby().(closer); ok {
+		cl.Close()
+	}
+}
+
+func (c *failoverClient) onStandby() Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.standby
+}
+
+// failover connects to the standby cluster if err says the primary is gone
+func (c *failoverClient) failover(err error) (Client, bool) {
+	if !utils.IsUnreachableErr(err) {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.standby != nil {
+		return c.standby, true
+	}
+	log.W("primary cluster unreachable:", err, ", connecting to standby", c.standbyAddrs)
+	standby, serr := tryNewTiKVClient(c.standbyAddrs, c.clientMode)
+	if serr != nil {
+		log.E("connect to standby cluster failed:", serr)
+		return nil, false
+	}
+	c.standby = standby
+	return standby, true
+}
+
+func (c *failoverClient) annotate() {
+	fmt.Fprintln(os.Stderr, color.YellowString("Note: served by the standby cluster %s (read-only)",
+		strings.Join(c.standbyAddrs, ",")))
+}
+
+// read runs fn on the standby if the session failed over, or on the primary,
+// failing over and retrying once if the primary is unreachable
+func (c *failoverClient) read(fn func(Client) error) error {
+	if standby := c.onStandby(); standby != nil {
+		if c.SnapshotTS() != 0 {
+			return errSnapshotOnStandby
+		}
+		defer c.annotate()
+		return fn(standby)
+	}
+	err := fn(c.primary)
+	if standby, ok := c.failover(err); ok {
+		// the snapshot is pinned on the primary only, reading the standby
+		// would silently leave it
+		if c.SnapshotTS() != 0 {
+			return fmt.Errorf("%v, %v", err, errSnapshotOnStandby)
+		}
+		defer c.annotate()
+		return fn(standby)
+	}
+	return err
+}
+
+// write runs fn on the primary, writes are never sent to the standby
+func (c *failoverClient) write(fn func(Client) error) error {
+	if c.onStandby() != nil {
+		return errStandbyReadOnly
+	}
+	err := fn(c.primary)
+	if _, ok := c.failover(err); ok {
+		return fmt.Errorf("%v, switched to the standby cluster, which is read-only", err)
+	}
+	return err
+}
+
+func (c *failoverClient) current() Client {
+	if standby := c.onStandby(); standby != nil {
+		return standby
+	}
+	return c.primary
+}
+
+func (c *failoverClient) GetClientMode() TiKV_MODE {
+	return c.current().GetClientMode()
+}
+
+func (c *failoverClient) GetClusterID() string {
+	return c.current().GetClusterID()
+}
+
+func (c *failoverClient) GetStores() ([]StoreInfo, error) {
+	var ret []StoreInfo
+	err := c.read(func(cli Client) error {
+		var err error
+		ret, err = cli.GetStores()
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) GetPDs() ([]PDInfo, error) {
+	var ret []PDInfo
+	err := c.read(func(cli Client) error {
+		var err error
+		ret, err = cli.GetPDs()
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) GetPDClient() pd.Client {
+	return c.current().GetPDClient()
+}
+
+func (c *failoverClient) Put(ctx context.Context, kv KV) error {
+	return c.write(func(cli Client) error { return cli.Put(ctx, kv) })
+}
+
+func (c *failoverClient) BatchPut(ctx context.Context, kvs []KV) error {
+	return c.write(func(cli Client) error { return cli.BatchPut(ctx, kvs) })
+}
+
+func (c *failoverClient) Get(ctx context.Context, k Key) (KV, error) {
+	var ret KV
+	err := c.read(func(cli Client) error {
+		var err error
+		ret, err = cli.Get(ctx, k)
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	var ret KVS
+	err := c.read(func(cli Client) error {
+		var err error
+		ret, err = cli.BatchGet(ctx, keys)
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) Scan(ctx context.Context, prefix []byte) (KVS, int, error) {
+	var ret KVS
+	var cnt int
+	err := c.read(func(cli Client) error {
+		var err error
+		ret, cnt, err = cli.Scan(ctx, prefix)
+		return err
+	})
+	return ret, cnt, err
+}
+
+func (c *failoverClient) Delete(ctx context.Context, k Key) error {
+	return c.write(func(cli Client) error { return cli.Delete(ctx, k) })
+}
+
+func (c *failoverClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	return c.write(func(cli Client) error { return cli.BatchDelete(ctx, kvs) })
+}
+
+func (c *failoverClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	var lastKey Key
+	var cnt int
+	err := c.write(func(cli Client) error {
+		var err error
+		lastKey, cnt, err = cli.DeletePrefix(ctx, prefix, limit)
+		return err
+	})
+	return lastKey, cnt, err
+}
+
+// OnStandby tells if the global client failed over to the standby cluster,
+// which is read-only
+func OnStandby() bool {
+	if c, ok := GetTiKVClient().(*failoverClient); ok {
+		return c.onStandby() != nil
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+// snapshotMemClient is a mem client whose reads can be pinned, like a txn
+// client
+type snapshotMemClient struct {
+	*memClient
+	ts uint64
+}
+
+func (c *snapshotMemClient) SetSnapshotTS(ts uint64) error { c.ts = ts; return nil }
+func (c *snapshotMemClient) SnapshotTS() uint64            { return c.ts }
+
+func TestFailoverReadWithSnapshot(t *testing.T) {
+	standby := newMemClient()
+	if err := standby.Put(context.TODO(), KV{K: Key("k"), V: Value("standby")}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		ts      uint64
+		wantErr bool
+	}{
+		{"not pinned", 0, false},
+		{"pinned", 449934215332872192, true},
+	}
+	for _, tt := range tests {
+		c := newFailoverClient(&snapshotMemClient{memClient: newMemClient()}, nil, "txn")
+		if err := c.SetSnapshotTS(tt.ts); err != nil {
+			t.Fatal(err)
+		}
+		// as if the primary had become unreachable
+		c.standby = standby
+		kv, err := c.Get(context.TODO(), Key("k"))
+		if tt.wantErr {
+			if err != errSnapshotOnStandby {
+				t.Errorf("%s: got %v, want %v", tt.name, err, errSnapshotOnStandby)
+			}
+			continue
+		}
+		if err != nil || string(kv.V) != "standby" {
+			t.Errorf("%s: got %q, %v, want the standby value", tt.name, kv.V, err)
+		}
+	}
+}
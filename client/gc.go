@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+// GCClient is implemented by clients of a transactional TiKV cluster
+type GCClient interface {
+	// GetGCSafePoint returns the safe point PD keeps for TiKV GC
+	GetGCSafePoint(ctx context.Context) (uint64, error)
+	// GetMinServiceSafePoint returns the smallest safe point registered by a
+	// service (BR, CDC, ...), 0 if there is none
+	GetMinServiceSafePoint(ctx context.Context) (uint64, error)
+	// GetCurrentTS returns a fresh timestamp from PD
+	GetCurrentTS(ctx context.Context) (uint64, error)
+	// GC resolves the locks before safePoint and advances the GC safe point
+	// to it, returns the new safe point
+	GC(ctx context.Context, safePoint uint64) (uint64, error)
+}
+
+// the service id tcli uses to read the service safe points
+const gcServiceID = "tcli"
+
+func (c *txnkvClient) GetGCSafePoint(ctx context.Context) (uint64, error) {
+	// the safe point never moves backwards, updating it to 0 reads it
+	return c.txnClient.GetPDClient().UpdateGCSafePoint(ctx, 0)
+}
+
+func (c *txnkvClient) GetMinServiceSafePoint(ctx context.Context) (uint64, error) {
+	// a ttl of 0 removes the safe point of tcli, which never registers one,
+	// and returns the minimum of the others
+	return c.txnClient.GetPDClient().UpdateServiceGCSafePoint(ctx, gcServiceID, 0, 0)
+}
+
+func (c *txnkvClient) GetCurrentTS(ctx context.Context) (uint64, error) {
+	physical, logical, err := c.txnClient.GetPDClient().GetTS(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return oracle.ComposeTS(physical, logical), nil
+}
+
+func (c *txnkvClient) GC(ctx context.Context, safePoint uint64) (uint64, error) {
+	return c.txnClient.GC(ctx, safePoint)
+}
+
+func asGCClient(cli Client) (GCClient, error) {
+	gc, ok := cli.(GCClient)
+	if !ok {
+		return nil, errors.New("gc is only supported in txn mode")
+	}
+	return gc, nil
+}
+
+func (c *failoverClient) GetGCSafePoint(ctx context.Context) (uint64, error) {
+	var ret uint64
+	err := c.read(func(cli Client) error {
+		gc, err := asGCClient(cli)
+		if err == nil {
+			ret, err = gc.GetGCSafePoint(ctx)
+		}
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) GetMinServiceSafePoint(ctx context.Context) (uint64, error) {
+	var ret uint64
+	err := c.read(func(cli Client) error {
+		gc, err := asGCClient(cli)
+		if err == nil {
+			ret, err = gc.GetMinServiceSafePoint(ctx)
+		}
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) GetCurrentTS(ctx context.Context) (uint64, error) {
+	var ret uint64
+	err := c.read(func(cli Client) error {
+		gc, err := asGCClient(cli)
+		if err == nil {
+			ret, err = gc.GetCurrentTS(ctx)
+		}
+		return err
+	})
+	return ret, err
+}
+
+func (c *failoverClient) GC(ctx context.Context, safePoint uint64) (uint64, error) {
+	var ret uint64
+	err := c.write(func(cli Client) error {
+		gc, err := asGCClient(cli)
+		if err == nil {
+			ret, err = gc.GC(ctx, safePoint)
+		}
+		return err
+	})
+	return ret, err
+}
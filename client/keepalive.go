@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/fatih/color"
+)
+
+// A TiKV session is probed every sys.keepalive_interval while the shell is
+// idle. Once a probe fails, the client is connected again before the next
+// command, with the state held by the client (a pinned snapshot) restored.
+// Variables and sysvars are kept by the shell and survive as they are.
+
+// how long a probe may take before the connection is considered lost
+const keepaliveTimeout = 10 * time.Second
+
+// a probe slower than this marks the connection as slow
+const slowProbeThreshold = 500 * time.Millisecond
+
+// Health is the state of the connection found by the last probe
+type Health string
+
+const (
+	HealthOK   Health = ""
+	HealthSlow Health = "slow"
+	HealthDown Health = "down"
+)
+
+var (
+	_reconnectMu sync.Mutex
+	// creates a new global client like the one connected at start, nil for
+	// local databases, which don't drop connections
+	_reconnect func() (Client, error)
+	// the error of the last probe, nil while the connection is fine
+	_connLost error
+	_health   Health
+	// called when the health changes, the shell shows it in the prompt
+	_onHealthChange func(Health)
+)
+
+// OnHealthChange sets fn to be called from the keepalive goroutine when the
+// connection becomes slow, down or healthy again
+func OnHealthChange(fn func(Health)) {
+	_reconnectMu.Lock()
+	defer _reconnectMu.Unlock()
+	_onHealthChange = fn
+}
+
+// CurrentHealth returns the health found by the last probe
+func CurrentHealth() Health {
+	_reconnectMu.Lock()
+	defer _reconnectMu.Unlock()
+	return _health
+}
+
+// setHealth must be called with _reconnectMu held, the returned function
+// reports the change and must be called once it is released
+func setHealth(h Health) func() {
+	if h == _health || _onHealthChange == nil {
+		_health = h
+		return func() {}
+	}
+	_health = h
+	fn := _onHealthChange
+	return func() { fn(h) }
+}
+
+func setReconnect(fn func() (Client, error)) {
+	_reconnectMu.Lock()
+	defer _reconnectMu.Unlock()
+	_reconnect = fn
+}
+
+// probe reads a key, which needs PD and TiKV to answer
+func probe(cli Client) error {
+	// a failover client would note the standby on stderr and could fail
+	// over on a single lost probe, probe the cluster it's connected to
+	if fc, ok := cli.(*failoverClient); ok {
+		cli = fc.current()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), keepaliveTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := cli.BatchGet(ctx, []Key{Key("\x00")})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(keepaliveTimeout):
+		return errors.New("no answer within " + keepaliveTimeout.String())
+	}
+}
+
+// StartKeepalive probes the global client in the background, it does
+// nothing for local databases
+func StartKeepalive() {
+	_reconnectMu.Lock()
+	enabled := _reconnect != nil
+	_reconnectMu.Unlock()
+	if !enabled {
+		return
+	}
+	go func() {
+		for {
+			v, _ := utils.SysVarGet(utils.SysVarKeepaliveKey)
+			interval, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil || interval <= 0 {
+				// disabled, don't keep showing a stale health, and check
+				// again later in case it's turned on
+				_reconnectMu.Lock()
+				notify := setHealth(HealthOK)
+				_reconnectMu.Unlock()
+				notify()
+				time.Sleep(time.Minute)
+				continue
+			}
+			time.Sleep(interval)
+			start := time.Now()
+			err = probe(GetTiKVClient())
+			h := HealthOK
+			switch {
+			case err != nil:
+				h = HealthDown
+			case time.Since(start) > slowProbeThreshold:
+				h = HealthSlow
+			}
+			_reconnectMu.Lock()
+			_connLost = err
+			notify := setHealth(h)
+			_reconnectMu.Unlock()
+			notify()
+		}
+	}()
+}
+
+// closer is implemented by the TiKV clients
+type closer interface {
+	Close()
+}
+
+// jobsRunning tells if background jobs may still use the current client
+func jobsRunning() bool {
+	for _, job := range utils.ListJobs() {
+		if job.Status().State == utils.JobRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureConnected connects the global client again if the last keepalive
+// probe failed, it's called before every command
+func EnsureConnected() {
+	// probing and connecting may take seconds, the keepalive goroutine and
+	// the prompt must not wait for them
+	_reconnectMu.Lock()
+	lost, reconnect := _connLost, _reconnect
+	_reconnectMu.Unlock()
+	if lost == nil || reconnect == nil {
+		return
+	}
+	old := GetTiKVClient()
+	// the connection may have come back by itself
+	if probe(old) == nil {
+		connected()
+		return
+	}
+	cli, err := reconnect()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Connection lost (%v), reconnect failed: %v", lost, err))
+		return
+	}
+	restored := ""
+	if ts := PinnedSnapshotTS(); ts != 0 {
+		if sc, ok := cli.(SnapshotClient); ok && sc.SetSnapshotTS(ts) == nil {
+			restored = ", snapshot " + utils.FormatTS(ts) + " restored"
+		}
+	}
+	_globalKvClient.Store(cli)
+	// a running job keeps the client it started with
+	if c, ok := old.(closer); ok && !jobsRunning() {
+		c.Close()
+	}
+	fmt.Fprintln(os.Stderr, color.YellowString("Connection lost (%v), reconnected%s", lost, restored))
+	connected()
+}
+
+// connected clears the lost connection
+func connected() {
+	_reconnectMu.Lock()
+	_connLost = nil
+	notify := setHealth(HealthOK)
+	_reconnectMu.Unlock()
+	notify()
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	levelutil "github.com/syndtr/goleveldb/leveldb/util"
+	pd "github.com/tikv/pd/client"
+)
+
+func newLevelDBClient(path string) (*leveldbClient, error) {
+	// never create an empty database when the path is mistyped
+	db, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return nil, err
+	}
+	return &leveldbClient{
+		db:   db,
+		path: path,
+	}, nil
+}
+
+type leveldbClient struct {
+	db   *leveldb.DB
+	path string
+}
+
+func (c *leveldbClient) Close() {
+	if c.db != nil {
+		c.db.Close()
+	}
+}
+
+func (c *leveldbClient) GetClientMode() TiKV_MODE {
+	return LEVELDB_CLIENT
+}
+
+func (c *leveldbClient) GetClusterID() string {
+	return c.path
+}
+
+func (c *leveldbClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("leveldbClient does not support GetStores()")
+}
+
+func (c *leveldbClient) GetPDs() ([]PDInfo, error) {
+	return nil, errors.New("leveldbClient does not support GetPDs()")
+}
+
+func (c *leveldbClient) GetPDClient() pd.Client {
+	panic("leveldbClient does not support GetPDClient()")
+}
+
+func (c *leveldbClient) Put(ctx context.Context, kv KV) error {
+	return c.db.Put(kv.K, kv.V, nil)
+}
+
+func (c *leveldbClient) BatchPut(ctx context.Context, kvs []KV) error {
+	batch := new(leveldb.Batch)
+	for _, kv := range kvs {
+		batch.Put(kv.K, kv.V)
+	}
+	return c.db.Write(batch, nil)
+}
+
+func (c *leveldbClient) Get(ctx context.Context, k Key) (KV, error) {
+	v, err := c.db.Get(k, nil)
+	if err == leveldb.ErrNotFound {
+		return KV{}, errors.New("key not found")
+	}
+	if err != nil {
+		return KV{}, err
+	}
+	return KV{K: k, V: v}, nil
+}
+
+func (c *leveldbClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	snap, err := c.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Release()
+	var ret KVS
+	for _, k := range keys {
+		v, err := snap.Get(k, nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, KV{K: k, V: v})
+	}
+	return ret, nil
+}
+
+func (c *leveldbClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	it := c.db.NewIterator(&levelutil.Range{Start: lower, Limit: upper}, nil)
+	defer it.Release()
+
+	var ret []KV
+	var lastKey KV
+	count := 0
+	first, next := it.First, it.Next
+	if reverse {
+		first, next = it.Last, it.Prev
+	}
+	for ok := first(); ok; ok = next() {
+		if !countOnly && limit == 0 {
+			break
+		}
+		// the iterator reuses its buffers, keep copies
+		k := append([]byte{}, it.Key()...)
+		// count only will not use limit
+		if !countOnly {
+			if keyOnly {
+				ret = append(ret, KV{K: k, V: nil})
+			} else {
+				ret = append(ret, KV{K: k, V: append([]byte{}, it.Value()...)})
+			}
+			limit--
+		}
+		count++
+		lastKey.K = k
+	}
+	if err := it.Error(); err != nil {
+		return nil, 0, err
+	}
+	if countOnly {
+		ret = append(ret, KV{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))})
+		ret = append(ret, KV{K: []byte("Last Key"), V: []byte(lastKey.K)})
+	}
+	return ret, count, nil
+}
+
+func (c *leveldbClient) Delete(ctx context.Context, k Key) error {
+	return c.db.Delete(k, nil)
+}
+
+func (c *leveldbClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	batch := new(leveldb.Batch)
+	for _, kv := range kvs {
+		batch.Delete(kv.K)
+	}
+	return c.db.Write(batch, nil)
+}
+
+// return lastKey, delete count, error
+func (c *leveldbClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	it := c.db.NewIterator(levelutil.BytesPrefix(prefix), nil)
+	defer it.Release()
+
+	var lastKey Key
+	batch := new(leveldb.Batch)
+	for limit > 0 && it.Next() {
+		lastKey = append([]byte{}, it.Key()...)
+		batch.Delete(lastKey)
+		limit--
+	}
+	if err := it.Error(); err != nil {
+		return nil, 0, err
+	}
+	if err := c.db.Write(batch, nil); err != nil {
+		return nil, 0, err
+	}
+	return lastKey, batch.Len(), nil
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	pd "github.com/tikv/pd/client"
+)
+
+func newMemClient() *memClient {
+	return &memClient{}
+}
+
+// memClient keeps all kv pairs in a sorted slice, it's used by offline mode
+type memClient struct {
+	mu  sync.RWMutex
+	kvs []KV
+}
+
+func (c *memClient) Close() {}
+
+func (c *memClient) GetClientMode() TiKV_MODE {
+	return MEM_CLIENT
+}
+
+func (c *memClient) GetClusterID() string {
+	return "offline"
+}
+
+func (c *memClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("memClient does not support GetStores()")
+}
+
+func (c *memClient) GetPDs() ([]PDInfo, error) {
+	return nil, errors.New("memClient does not support GetPDs()")
+}
+
+func (c *memClient) GetPDClient() pd.Client {
+	panic("memClient does not support GetPDClient()")
+}
+
+// LoadCsv loads a csv file written by the backup command,
+// the "Key,Value" header line is skipped if present
+func (c *memClient) LoadCsv(fname string) (int, error) {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return 0, err
+	}
+	defer fp.Close()
+
+	r := csv.NewReader(fp)
+	var batch []KV
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, err
+		}
+		if len(rec) != 2 {
+			return 0, fmt.Errorf("invalid csv record: %v, format should be: <key>,<value>", rec)
+		}
+		if len(batch) == 0 && rec[0] == "Key" && rec[1] == "Value" {
+			continue
+		}
+		k, err := utils.GetStringLit(rec[0])
+		if err != nil {
+			return 0, err
+		}
+		// empty values are valid, GetStringLit doesn't accept them
+		var v []byte
+		if rec[1] != "" {
+			if v, err = utils.GetStringLit(rec[1]); err != nil {
+				return 0, err
+			}
+		}
+		batch = append(batch, KV{K: k, V: v})
+	}
+	return len(batch), c.BatchPut(context.TODO(), batch)
+}
+
+// seek returns the position of the first key >= k, must be called with lock held
+func (c *memClient) seek(k []byte) int {
+	return sort.Search(len(c.kvs), func(i int) bool {
+		return bytes.Compare(c.kvs[i].K, k) >= 0
+	})
+}
+
+func (c *memClient) Put(ctx context.Context, kv KV) error {
+	return c.BatchPut(ctx, []KV{kv})
+}
+
+func (c *memClient) BatchPut(ctx context.Context, kvs []KV) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, kv := range kvs {
+		kv = KV{K: append([]byte{}, kv.K...), V: append([]byte{}, kv.V...)}
+		pos := c.seek(kv.K)
+		if pos < len(c.kvs) && bytes.Equal(c.kvs[pos].K, kv.K) {
+			c.kvs[pos] = kv
+			continue
+		}
+		c.kvs = append(c.kvs, KV{})
+		copy(c.kvs[pos+1:], c.kvs[pos:])
+		c.kvs[pos] = kv
+	}
+	return nil
+}
+
+func (c *memClient) Get(ctx context.Context, k Key) (KV, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	pos := c.seek(k)
+	if pos == len(c.kvs) || !bytes.Equal(c.kvs[pos].K, k) {
+		return KV{}, errors.New("key not found")
+	}
+	return c.kvs[pos], nil
+}
+
+func (c *memClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var ret KVS
+	for _, k := range keys {
+		pos := c.seek(k)
+		if pos < len(c.kvs) && bytes.Equal(c.kvs[pos].K, k) {
+			ret = append(ret, c.kvs[pos])
+		}
+	}
+	return ret, nil
+}
+
+func (c *memClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// kvs are sorted, take the range and walk it in scan order
+	from, to := c.seek(lower), len(c.kvs)
+	if upper != nil {
+		to = c.seek(upper)
+	}
+
+	var ret []KV
+	var lastKey KV
+	count := 0
+	for j := 0; j < to-from; j++ {
+		kv := c.kvs[from+j]
+		if reverse {
+			kv = c.kvs[to-1-j]
+		}
+		if !countOnly && limit == 0 {
+			break
+		}
+		// count only will not use limit
+		if !countOnly {
+			if keyOnly {
+				ret = append(ret, KV{K: kv.K, V: nil})
+			} else {
+				ret = append(ret, kv)
+			}
+			limit--
+		}
+		count++
+		lastKey.K = kv.K
+	}
+	if countOnly {
+		ret = append(ret, KV{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))})
+		ret = append(ret, KV{K: []byte("Last Key"), V: []byte(lastKey.K)})
+	}
+	return ret, count, nil
+}
+
+func (c *memClient) Delete(ctx context.Context, k Key) error {
+	return c.BatchDelete(ctx, []KV{{K: k}})
+}
+
+func (c *memClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, kv := range kvs {
+		pos := c.seek(kv.K)
+		if pos < len(c.kvs) && bytes.Equal(c.kvs[pos].K, kv.K) {
+			c.kvs = append(c.kvs[:pos], c.kvs[pos+1:]...)
+		}
+	}
+	return nil
+}
+
+// return lastKey, delete count, error
+func (c *memClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start := c.seek(prefix)
+	end := start
+	for end < len(c.kvs) && end-start < limit && bytes.HasPrefix(c.kvs[end].K, prefix) {
+		end++
+	}
+	if end == start {
+		return nil, 0, nil
+	}
+	lastKey := c.kvs[end-1].K
+	c.kvs = append(c.kvs[:start], c.kvs[end:]...)
+	return lastKey, end - start, nil
+}
@@ -1,12 +1,10 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 
-	"github.com/c4pt0r/log"
 	"github.com/c4pt0r/tcli"
 	"github.com/c4pt0r/tcli/utils"
 	"github.com/tikv/client-go/v2/config"
@@ -16,15 +14,15 @@ import (
 
 var MaxRawKVScanLimit = 10240
 
-func newRawKVClient(pdAddr []string) *rawkvClient {
+func newRawKVClient(pdAddr []string) (*rawkvClient, error) {
 	client, err := rawkv.NewClient(context.TODO(), pdAddr, config.DefaultConfig().Security)
 	if err != nil {
-		log.F(err)
+		return nil, err
 	}
 	return &rawkvClient{
 		rawClient: client,
 		pdAddr:    pdAddr,
-	}
+	}, nil
 }
 
 type rawkvClient struct {
@@ -83,44 +81,86 @@ func (c *rawkvClient) Get(ctx context.Context, k Key) (KV, error) {
 	return KV{k, v}, nil
 }
 
+func (c *rawkvClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	ks := make([][]byte, len(keys))
+	for i, k := range keys {
+		ks[i] = k
+	}
+	values, err := c.rawClient.BatchGet(ctx, ks)
+	if err != nil {
+		return nil, err
+	}
+	var ret KVS
+	for i, v := range values {
+		if v != nil {
+			ret = append(ret, KV{K: keys[i], V: v})
+		}
+	}
+	return ret, nil
+}
+
 func (c *rawkvClient) Scan(ctx context.Context, prefix []byte) (KVS, int, error) {
 	scanOpts := utils.PropFromContext(ctx)
 
-	strictPrefix := scanOpts.GetBool(tcli.ScanOptStrictPrefix, false)
 	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
 	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
 	// count only mode will ignore this
 	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, prefix)
+	if err != nil {
+		return nil, 0, err
+	}
 	if countOnly {
-		// BUG, TODO
-		limit = MaxRawKVScanLimit
+		return c.count(ctx, lower, upper)
 	}
 
-	keys, values, err := c.rawClient.Scan(ctx, prefix, []byte{}, limit)
+	var keys, values [][]byte
+	if reverse {
+		if upper == nil {
+			return nil, 0, errReverseScanNoUpperBound
+		}
+		keys, values, err = c.rawClient.ReverseScan(ctx, upper, lower, limit)
+	} else {
+		keys, values, err = c.rawClient.Scan(ctx, lower, upper, limit)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var ret []KV
-	var lastKey KV
-	count := 0
 	for i := 0; i < len(keys); i++ {
-		if strictPrefix && !bytes.HasPrefix(keys[i], prefix) {
-			break
+		if keyOnly {
+			ret = append(ret, KV{K: keys[i], V: nil})
+		} else {
+			ret = append(ret, KV{K: keys[i], V: values[i]})
+		}
+	}
+	return ret, len(ret), nil
+}
+
+// count counts keys in [startKey, endKey) in batches of MaxRawKVScanLimit,
+// a single raw scan can't return more. This version of the raw API has no
+// key-only scan, the end key keeps it from reading past the range.
+func (c *rawkvClient) count(ctx context.Context, startKey, endKey []byte) (KVS, int, error) {
+	var lastKey []byte
+	count := 0
+	for {
+		keys, _, err := c.rawClient.Scan(ctx, startKey, endKey, MaxRawKVScanLimit)
+		if err != nil {
+			return nil, 0, err
 		}
-		if !countOnly {
-			if keyOnly {
-				ret = append(ret, KV{K: keys[i], V: nil})
-			} else {
-				ret = append(ret, KV{K: keys[i], V: values[i]})
-			}
+		count += len(keys)
+		if len(keys) > 0 {
+			lastKey = keys[len(keys)-1]
 		}
-		count++
-		lastKey.K = keys[i]
+		if len(keys) < MaxRawKVScanLimit {
+			break
+		}
+		startKey = utils.NextKey(lastKey)
 	}
-	if countOnly {
-		ret = append(ret, KV{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))})
-		ret = append(ret, KV{K: []byte("Last Key"), V: []byte(lastKey.K)})
+	ret := []KV{
+		{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", count))},
+		{K: []byte("Last Key"), V: lastKey},
 	}
 	return ret, count, nil
 }
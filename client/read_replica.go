@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/fatih/color"
+)
+
+// Read policies of scans, set with the sys.read_policy variable. Any other
+// value is the name of the read replica to scan.
+const (
+	// scans only read the primary cluster, the default
+	ReadPolicyPrimary = "primary"
+	// scans take turns between the primary and the read replicas
+	ReadPolicyRoundRobin = "round-robin"
+	// scans take turns between the read replicas only
+	ReadPolicyReplicas = "replicas"
+)
+
+// readReplica is a cluster holding the same data as the primary, scans may
+// read it to take read-only traffic off the primary. It's connected on
+// first use.
+type readReplica struct {
+	name    string
+	pdAddrs []string
+	cli     Client
+}
+
+var (
+	_readReplicaMu   sync.Mutex
+	_readReplicas    []*readReplica
+	_readReplicaMode string
+	// the next candidate of round-robin policies
+	_readReplicaNext int
+)
+
+// InitReadReplicas registers the read replicas of spec, formatted as
+// "name=pd1,pd2;name2=pd3". Replicas use the client mode of the primary.
+func InitReadReplicas(spec string, clientMode string) error {
+	switch strings.ToLower(clientMode) {
+	case "txn", "raw":
+	default:
+		return utils.NewParseError("read replicas are only supported in txn and raw mode")
+	}
+	replicas, err := parseReadReplicas(spec)
+	if err != nil {
+		return err
+	}
+
+	_readReplicaMu.Lock()
+	defer _readReplicaMu.Unlock()
+	_readReplicas = replicas
+	_readReplicaMode = clientMode
+	return nil
+}
+
+// parseReadReplicas parses "name=pd1,pd2;name2=pd3"
+func parseReadReplicas(spec string) ([]*readReplica, error) {
+	var replicas []*readReplica
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, utils.NewParseError("invalid read replica %q, should be name=pd1,pd2", item)
+		}
+		switch name {
+		case ReadPolicyPrimary, ReadPolicyRoundRobin, ReadPolicyReplicas:
+			return nil, utils.NewParseError("%q is a read policy, it can't name a read replica", name)
+		}
+		if seen[name] {
+			return nil, utils.NewParseError("duplicated read replica %q", name)
+		}
+		seen[name] = true
+		var pdAddrs []string
+		for _, addr := range strings.Split(parts[1], ",") {
+			if addr = strings.TrimSpace(addr); addr == "" {
+				return nil, utils.NewParseError("invalid read replica %q, empty PD address", item)
+			}
+			pdAddrs = append(pdAddrs, addr)
+		}
+		replicas = append(replicas, &readReplica{name: name, pdAddrs: pdAddrs})
+	}
+	return replicas, nil
+}
+
+// ReadReplicaNames returns the read replicas and their PD addresses
+func ReadReplicaNames() []string {
+	_readReplicaMu.Lock()
+	defer _readReplicaMu.Unlock()
+	var names []string
+	for _, r := range _readReplicas {
+		names = append(names, fmt.Sprintf("%s=%s", r.name, strings.Join(r.pdAddrs, ",")))
+	}
+	return names
+}
+
+// connect returns the client of r, connecting it if needed
+func (r *readReplica) connect() (Client, error) {
+	if r.cli != nil {
+		return r.cli, nil
+	}
+	cli, err := tryNewTiKVClient(r.pdAddrs, _readReplicaMode)
+	if err != nil {
+		return nil, err
+	}
+	r.cli = cli
+	return cli, nil
+}
+
+// GetScanClient returns the client a read-only scan should use following
+// sys.read_policy: the primary client or one of the read replicas. A
+// replica that can't be connected is skipped for the primary, unless it
+// was asked for by name.
+func GetScanClient() (Client, error) {
+	policy, _ := utils.SysVarGet(utils.SysVarReadPolicyKey)
+	// replicas have their own timestamps, a pinned snapshot reads the primary
+	if policy == "" || policy == ReadPolicyPrimary || PinnedSnapshotTS() != 0 {
+		return GetTiKVClient(), nil
+	}
+
+	_readReplicaMu.Lock()
+	defer _readReplicaMu.Unlock()
+	var candidates []*readReplica
+	switch policy {
+	case ReadPolicyRoundRobin:
+		// nil stands for the primary
+		candidates = append([]*readReplica{nil}, _readReplicas...)
+	case ReadPolicyReplicas:
+		candidates = _readReplicas
+	default:
+		for _, r := range _readReplicas {
+			if r.name == policy {
+				cli, err := r.connect()
+				if err != nil {
+					return nil, fmt.Errorf("connect to read replica %s failed: %v", r.name, err)
+				}
+				annotateReadReplica(r)
+				return cli, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown read policy or replica %q", policy)
+	}
+	if len(candidates) == 0 {
+		return GetTiKVClient(), nil
+	}
+
+	r := candidates[_readReplicaNext%len(candidates)]
+	_readReplicaNext++
+	if r == nil {
+		return GetTiKVClient(), nil
+	}
+	cli, err := r.connect()
+	if err != nil {
+		log.W("connect to read replica", r.name, "failed:", err, ", reading the primary cluster")
+		return GetTiKVClient(), nil
+	}
+	annotateReadReplica(r)
+	return cli, nil
+}
+
+func annotateReadReplica(r *readReplica) {
+	fmt.Fprintln(os.Stderr, color.YellowString("Note: served by read replica %s", r.name))
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReadReplicas(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string][]string
+		names   []string
+		wantErr bool
+	}{
+		{spec: "", names: nil},
+		{spec: "dc2=pd1:2379", names: []string{"dc2"}, want: map[string][]string{"dc2": {"pd1:2379"}}},
+		{
+			spec:  " dc2 = pd1:2379, pd2:2379 ;dc3=pd3:2379;",
+			names: []string{"dc2", "dc3"},
+			want:  map[string][]string{"dc2": {"pd1:2379", "pd2:2379"}, "dc3": {"pd3:2379"}},
+		},
+		{spec: "dc2", wantErr: true},
+		{spec: "=pd1:2379", wantErr: true},
+		{spec: "dc2=", wantErr: true},
+		{spec: "dc2=pd1:2379,,pd2:2379", wantErr: true},
+		{spec: "dc2=pd1:2379;dc2=pd2:2379", wantErr: true},
+		{spec: "primary=pd1:2379", wantErr: true},
+		{spec: "round-robin=pd1:2379", wantErr: true},
+		{spec: "replicas=pd1:2379", wantErr: true},
+	}
+	for _, tt := range tests {
+		replicas, err := parseReadReplicas(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.spec, err)
+			continue
+		}
+		var names []string
+		for _, r := range replicas {
+			names = append(names, r.name)
+			if !reflect.DeepEqual(r.pdAddrs, tt.want[r.name]) {
+				t.Errorf("%q: %s has PD %v, want %v", tt.spec, r.name, r.pdAddrs, tt.want[r.name])
+			}
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("%q: got replicas %v, want %v", tt.spec, names, tt.names)
+		}
+	}
+}
+
+func TestInitReadReplicasMode(t *testing.T) {
+	for _, mode := range []string{"bolt", "offline", "redis"} {
+		if err := InitReadReplicas("dc2=pd1:2379", mode); err == nil {
+			t.Errorf("mode %s: expected an error", mode)
+		}
+	}
+}
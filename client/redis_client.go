@@ -0,0 +1,344 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/gomodule/redigo/redis"
+	pd "github.com/tikv/pd/client"
+)
+
+// how many keys are sent in a single MGET / DEL / SCAN round trip
+var RedisBatchSize = 1000
+
+func newRedisClient(addr string) (*redisClient, error) {
+	pool := &redis.Pool{
+		MaxIdle:     4,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr)
+		},
+	}
+	conn := pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PING"); err != nil {
+		return nil, err
+	}
+	return &redisClient{
+		pool: pool,
+		addr: addr,
+	}, nil
+}
+
+// redisClient maps SCAN/MGET onto the Client interface. Only string values
+// are supported, other types are skipped by scans.
+//
+// Redis has no ordered iteration: every scan walks the keys matching the
+// longest prefix of its range with SCAN and sorts them on the client,
+// keeping only the first limit ones. A scan bounded by neither a prefix nor
+// --end walks the whole keyspace, once per batch when it's paged, which is
+// slow on large keyspaces. The result is not a snapshot, keys written during
+// the scan may or may not show up.
+type redisClient struct {
+	pool *redis.Pool
+	addr string
+}
+
+func (c *redisClient) Close() {
+	if c.pool != nil {
+		c.pool.Close()
+	}
+}
+
+func (c *redisClient) GetClientMode() TiKV_MODE {
+	return REDIS_CLIENT
+}
+
+func (c *redisClient) GetClusterID() string {
+	return c.addr
+}
+
+func (c *redisClient) GetStores() ([]StoreInfo, error) {
+	return nil, errors.New("redisClient does not support GetStores()")
+}
+
+func (c *redisClient) GetPDs() ([]PDInfo, error) {
+	return nil, errors.New("redisClient does not support GetPDs()")
+}
+
+func (c *redisClient) GetPDClient() pd.Client {
+	panic("redisClient does not support GetPDClient()")
+}
+
+func (c *redisClient) Put(ctx context.Context, kv KV) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", []byte(kv.K), []byte(kv.V))
+	return err
+}
+
+func (c *redisClient) BatchPut(ctx context.Context, kvs []KV) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	for len(kvs) > 0 {
+		n := len(kvs)
+		if n > RedisBatchSize {
+			n = RedisBatchSize
+		}
+		args := redis.Args{}
+		for _, kv := range kvs[:n] {
+			args = args.Add([]byte(kv.K), []byte(kv.V))
+		}
+		if _, err := conn.Do("MSET", args...); err != nil {
+			return err
+		}
+		kvs = kvs[n:]
+	}
+	return nil
+}
+
+func (c *redisClient) Get(ctx context.Context, k Key) (KV, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+	v, err := redis.Bytes(conn.Do("GET", []byte(k)))
+	if err == redis.ErrNil {
+		return KV{}, errors.New("key not found")
+	}
+	if err != nil {
+		return KV{}, err
+	}
+	return KV{K: k, V: v}, nil
+}
+
+func (c *redisClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+	var ret KVS
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > RedisBatchSize {
+			n = RedisBatchSize
+		}
+		// redigo only writes plain []byte args as bytes
+		batch := make([][]byte, n)
+		for i, k := range keys[:n] {
+			batch[i] = k
+		}
+		values, err := redis.Values(conn.Do("MGET", redis.Args{}.AddFlat(batch)...))
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			// nil for missing keys and non-string values
+			if v != nil {
+				ret = append(ret, KV{K: keys[i], V: v.([]byte)})
+			}
+		}
+		keys = keys[n:]
+	}
+	return ret, nil
+}
+
+// escapeGlob escapes the glob meta characters used by SCAN MATCH
+func escapeGlob(s []byte) string {
+	var sb strings.Builder
+	for _, ch := range s {
+		switch ch {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
+
+// keyCollector keeps the first max keys of a scan in scan order, or all of
+// them if max <= 0, sorting as it goes so memory stays about 2*max keys
+type keyCollector struct {
+	keys    [][]byte
+	reverse bool
+	max     int
+}
+
+func (kc *keyCollector) add(k []byte) {
+	kc.keys = append(kc.keys, k)
+	if kc.max > 0 && len(kc.keys) >= 2*kc.max {
+		kc.trim()
+	}
+}
+
+func (kc *keyCollector) trim() {
+	sort.Slice(kc.keys, func(i, j int) bool {
+		return (bytes.Compare(kc.keys[i], kc.keys[j]) < 0) != kc.reverse
+	})
+	if kc.max > 0 && len(kc.keys) > kc.max {
+		kc.keys = kc.keys[:kc.max]
+	}
+}
+
+// scanRange calls fn for every key in [lower, upper) matching prefix, in
+// no particular order
+func (c *redisClient) scanRange(conn redis.Conn, prefix []byte, lower, upper []byte, fn func(k []byte)) error {
+	match := escapeGlob(prefix) + "*"
+	cursor := "0"
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", match, "COUNT", RedisBatchSize))
+		if err != nil {
+			return err
+		}
+		if cursor, err = redis.String(reply[0], nil); err != nil {
+			return err
+		}
+		batch, err := redis.ByteSlices(reply[1], nil)
+		if err != nil {
+			return err
+		}
+		for _, k := range batch {
+			if bytes.Compare(k, lower) >= 0 && (upper == nil || bytes.Compare(k, upper) < 0) {
+				fn(k)
+			}
+		}
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// scanKeys returns the first max keys of [lower, upper) in scan order, all
+// of them if max <= 0
+func (c *redisClient) scanKeys(conn redis.Conn, lower, upper []byte, reverse bool, max int) ([][]byte, error) {
+	kc := &keyCollector{reverse: reverse, max: max}
+	if err := c.scanRange(conn, rangePrefix(lower, upper), lower, upper, kc.add); err != nil {
+		return nil, err
+	}
+	kc.trim()
+	return kc.keys, nil
+}
+
+func (c *redisClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
+	scanOpts := utils.PropFromContext(ctx)
+
+	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
+	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// count only mode will ignore this
+	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	if countOnly {
+		// the last key in scan order
+		var cnt int
+		var lastKey []byte
+		err := c.scanRange(conn, rangePrefix(lower, upper), lower, upper, func(k []byte) {
+			cnt++
+			if lastKey == nil || (bytes.Compare(k, lastKey) > 0) != reverse {
+				lastKey = k
+			}
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		ret := []KV{
+			{K: []byte("Count"), V: []byte(fmt.Sprintf("%d", cnt))},
+			{K: []byte("Last Key"), V: lastKey},
+		}
+		return ret, cnt, nil
+	}
+
+	if limit <= 0 {
+		return nil, 0, nil
+	}
+	keys, err := c.scanKeys(conn, lower, upper, reverse, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ret []KV
+	if keyOnly {
+		for _, k := range keys {
+			ret = append(ret, KV{K: k, V: nil})
+		}
+		return ret, len(ret), nil
+	}
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > RedisBatchSize {
+			n = RedisBatchSize
+		}
+		values, err := redis.Values(conn.Do("MGET", redis.Args{}.AddFlat(keys[:n])...))
+		if err != nil {
+			return nil, 0, err
+		}
+		for i, v := range values {
+			// nil for non-string values, or keys deleted since SCAN
+			if v == nil {
+				continue
+			}
+			ret = append(ret, KV{K: keys[i], V: v.([]byte)})
+		}
+		keys = keys[n:]
+	}
+	return ret, len(ret), nil
+}
+
+func (c *redisClient) Delete(ctx context.Context, k Key) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("DEL", []byte(k))
+	return err
+}
+
+func (c *redisClient) BatchDelete(ctx context.Context, kvs []KV) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	for len(kvs) > 0 {
+		n := len(kvs)
+		if n > RedisBatchSize {
+			n = RedisBatchSize
+		}
+		args := redis.Args{}
+		for _, kv := range kvs[:n] {
+			args = args.Add([]byte(kv.K))
+		}
+		if _, err := conn.Do("DEL", args...); err != nil {
+			return err
+		}
+		kvs = kvs[n:]
+	}
+	return nil
+}
+
+// return lastKey, delete count, error
+func (c *redisClient) DeletePrefix(ctx context.Context, prefix Key, limit int) (Key, int, error) {
+	conn := c.pool.Get()
+	if limit <= 0 {
+		return nil, 0, nil
+	}
+	keys, err := c.scanKeys(conn, prefix, utils.PrefixNextKey(prefix), false, limit)
+	conn.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(keys) == 0 {
+		return nil, 0, nil
+	}
+	var batch []KV
+	for _, k := range keys {
+		batch = append(batch, KV{K: k})
+	}
+	if err := c.BatchDelete(ctx, batch); err != nil {
+		return nil, 0, err
+	}
+	return keys[len(keys)-1], len(keys), nil
+}
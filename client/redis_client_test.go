@@ -0,0 +1,43 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestKeyCollector(t *testing.T) {
+	var keys [][]byte
+	for _, i := range rand.New(rand.NewSource(1)).Perm(1000) {
+		keys = append(keys, []byte(fmt.Sprintf("k%04d", i)))
+	}
+	tests := []struct {
+		reverse     bool
+		max         int
+		first, last string
+		want        int
+	}{
+		{false, 10, "k0000", "k0009", 10},
+		{true, 10, "k0999", "k0990", 10},
+		{false, 0, "k0000", "k0999", 1000},
+		{true, 0, "k0999", "k0000", 1000},
+		{false, 2000, "k0000", "k0999", 1000},
+	}
+	for _, tt := range tests {
+		kc := &keyCollector{reverse: tt.reverse, max: tt.max}
+		peak := 0
+		for _, k := range keys {
+			kc.add(k)
+			if len(kc.keys) > peak {
+				peak = len(kc.keys)
+			}
+		}
+		kc.trim()
+		if len(kc.keys) != tt.want || string(kc.keys[0]) != tt.first || string(kc.keys[len(kc.keys)-1]) != tt.last {
+			t.Errorf("%+v: got %d keys from %s to %s", tt, len(kc.keys), kc.keys[0], kc.keys[len(kc.keys)-1])
+		}
+		if tt.max > 0 && peak >= 2*tt.max {
+			t.Errorf("%+v: kept %d keys at once", tt, peak)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tikv/client-go/v2/util/codec"
+)
+
+// RegionStats sums the statistics PD keeps for the regions of a key range.
+// PD only knows whole regions, so the regions at both ends of the range are
+// counted in full.
+type RegionStats struct {
+	Regions         int
+	ApproximateKeys int64
+	// in MiB
+	ApproximateSize int64
+	// the same stats split by the store holding the region leader, regions
+	// without a leader are counted under store 0
+	Leaders map[uint64]*RegionStats
+}
+
+func (s *RegionStats) add(r pdRegion) {
+	s.Regions++
+	s.ApproximateKeys += r.ApproximateKeys
+	s.ApproximateSize += r.ApproximateSize
+}
+
+// RegionStatsClient is implemented by clients connected to a TiKV cluster
+type RegionStatsClient interface {
+	// GetRegionStats returns the stats of regions in [startKey, endKey),
+	// an empty endKey means no upper bound
+	GetRegionStats(ctx context.Context, startKey, endKey []byte) (RegionStats, error)
+}
+
+// how many regions are fetched from PD per request
+var PDRegionBatchSize = 256
+
+type pdRegion struct {
+	StartKey        string `json:"start_key"`
+	EndKey          string `json:"end_key"`
+	ApproximateSize int64  `json:"approximate_size"`
+	ApproximateKeys int64  `json:"approximate_keys"`
+	Leader          struct {
+		StoreID uint64 `json:"store_id"`
+	} `json:"leader"`
+}
+
+type pdRegions struct {
+	Count   int        `json:"count"`
+	Regions []pdRegion `json:"regions"`
+}
+
+func scanPDRegions(ctx context.Context, pdAddr string, key []byte) ([]pdRegion, error) {
+	if !strings.Contains(pdAddr, "://") {
+		pdAddr = "http://" + pdAddr
+	}
+	u := fmt.Sprintf("%s/pd/api/v1/regions/key?key=%s&limit=%d", strings.TrimRight(pdAddr, "/"),
+		url.QueryEscape(string(key)), PDRegionBatchSize)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pd returned %s", resp.Status)
+	}
+	var ret pdRegions
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return nil, err
+	}
+	return ret.Regions, nil
+}
+
+// getRegionStats walks the regions of [startKey, endKey) through the PD
+// HTTP API, keys must already be encoded the way TiKV stores them
+func getRegionStats(ctx context.Context, pdAddr string, startKey, endKey []byte) (RegionStats, error) {
+	stats := RegionStats{Leaders: make(map[uint64]*RegionStats)}
+	key := startKey
+	for {
+		regions, err := scanPDRegions(ctx, pdAddr, key)
+		if err != nil {
+			return stats, err
+		}
+		if len(regions) == 0 {
+			return stats, nil
+		}
+		for _, r := range regions {
+			regionStart, err := hex.DecodeString(r.StartKey)
+			if err != nil {
+				return stats, err
+			}
+			if len(endKey) > 0 && bytes.Compare(regionStart, endKey) >= 0 {
+				return stats, nil
+			}
+			stats.add(r)
+			leader, ok := stats.Leaders[r.Leader.StoreID]
+			if !ok {
+				leader = &RegionStats{}
+				stats.Leaders[r.Leader.StoreID] = leader
+			}
+			leader.add(r)
+			regionEnd, err := hex.DecodeString(r.EndKey)
+			if err != nil {
+				return stats, err
+			}
+			// the last region
+			if len(regionEnd) == 0 {
+				return stats, nil
+			}
+			key = regionEnd
+		}
+	}
+}
+
+func (c *txnkvClient) GetRegionStats(ctx context.Context, startKey, endKey []byte) (RegionStats, error) {
+	// txn keys are stored memcomparable encoded
+	start := codec.EncodeBytes(nil, startKey)
+	var end []byte
+	if len(endKey) > 0 {
+		end = codec.EncodeBytes(nil, endKey)
+	}
+	return getRegionStats(ctx, c.txnClient.GetPDClient().GetLeaderAddr(), start, end)
+}
+
+func (c *rawkvClient) GetRegionStats(ctx context.Context, startKey, endKey []byte) (RegionStats, error) {
+	if len(c.pdAddr) == 0 {
+		return RegionStats{}, errors.New("no pd address")
+	}
+	return getRegionStats(ctx, c.pdAddr[0], startKey, endKey)
+}
+
+func (c *failoverClient) GetRegionStats(ctx context.Context, startKey, endKey []byte) (RegionStats, error) {
+	var stats RegionStats
+	err := c.read(func(cli Client) error {
+		rc, ok := cli.(RegionStatsClient)
+		if !ok {
+			return errors.New("region stats are not supported")
+		}
+		var err error
+		stats, err = rc.GetRegionStats(ctx, startKey, endKey)
+		return err
+	})
+	return stats, err
+}
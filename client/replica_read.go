@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tikv/client-go/v2/kv"
+)
+
+// ReplicaReadClient is implemented by clients of a transactional TiKV
+// cluster
+type ReplicaReadClient interface {
+	// GetCurrentTS returns a fresh timestamp from PD
+	GetCurrentTS(ctx context.Context) (uint64, error)
+	// ScanReplica reads up to limit keys of [startKey, endKey) at ts from
+	// the region leaders, or from a follower of each region, an empty
+	// endKey means no upper bound
+	ScanReplica(ctx context.Context, startKey, endKey []byte, ts uint64, follower bool, limit int) (KVS, error)
+}
+
+func (c *txnkvClient) ScanReplica(ctx context.Context, startKey, endKey []byte, ts uint64, follower bool, limit int) (KVS, error) {
+	snapshot := c.txnClient.GetSnapshot(ts)
+	if follower {
+		snapshot.SetReplicaRead(kv.ReplicaReadFollower)
+	}
+	it, err := snapshot.Iter(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var ret KVS
+	for it.Valid() && len(ret) < limit {
+		ret = append(ret, KV{K: it.Key()[:], V: it.Value()[:]})
+		if err := it.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (c *failoverClient) ScanReplica(ctx context.Context, startKey, endKey []byte, ts uint64, follower bool, limit int) (KVS, error) {
+	var ret KVS
+	err := c.read(func(cli Client) error {
+		rc, ok := cli.(ReplicaReadClient)
+		if !ok {
+			return errors.New("replica reads are only supported in txn mode")
+		}
+		var err error
+		ret, err = rc.ScanReplica(ctx, startKey, endKey, ts, follower, limit)
+		return err
+	})
+	return ret, err
+}
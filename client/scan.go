@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+// scanBounds returns the key range [lower, upper) of a scan and whether it
+// runs backwards, nil bounds are open. A forward scan goes from startKey up
+// to --end, a reverse one from just before startKey down to --end, an empty
+// startKey starts from the last key. strict-prefix limits the range to keys
+// with the startKey prefix.
+func scanBounds(scanOpts *properties.Properties, startKey []byte) (lower, upper []byte, reverse bool, err error) {
+	reverse = scanOpts.GetBool(tcli.ScanOptReverse, false)
+	var end []byte
+	if s := scanOpts.GetString(tcli.ScanOptEnd, ""); s != "" {
+		if end, err = utils.GetStringLit(s); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if scanOpts.GetBool(tcli.ScanOptStrictPrefix, false) {
+		lower, upper = startKey, utils.PrefixNextKey(startKey)
+		switch {
+		case end == nil:
+		case reverse && bytes.Compare(end, lower) > 0:
+			lower = end
+		case !reverse && (upper == nil || bytes.Compare(end, upper) < 0):
+			upper = end
+		}
+		return lower, upper, reverse, nil
+	}
+	if !reverse {
+		return startKey, end, false, nil
+	}
+	if len(startKey) == 0 {
+		startKey = nil
+	}
+	return end, startKey, true, nil
+}
+
+// rangePrefix returns the longest prefix shared by every key in
+// [lower, upper), so stores that can only match keys by pattern don't walk
+// the others. A key in the range starts with lower[:n] if it sorts before
+// the first key past that prefix.
+func rangePrefix(lower, upper []byte) []byte {
+	if upper == nil {
+		return nil
+	}
+	for n := len(lower); n > 0; n-- {
+		next := utils.PrefixNextKey(lower[:n])
+		if next == nil || bytes.Compare(next, upper) >= 0 {
+			return lower[:n]
+		}
+	}
+	return nil
+}
+
+// TiKV locates the region of the upper bound to scan backwards, it can't
+// start from the last key
+var errReverseScanNoUpperBound = errors.New("reverse scan needs a start key on TiKV")
+
+// afterScanRange tells if k is past the end of the scan range
+func afterScanRange(k, lower, upper []byte, reverse bool) bool {
+	if reverse {
+		return bytes.Compare(k, lower) < 0
+	}
+	return upper != nil && bytes.Compare(k, upper) >= 0
+}
+
+// ScanBatches reads c from startKey in batches of batchSize and calls fn for
+// each of them. It stops at the first key without prefix if prefix isn't
+// nil, after limit kv pairs if limit is positive, or when ctx is done.
+// strict-prefix matches against the start key, which moves with every
+// batch, so the prefix is checked here instead, and bounds the scan with
+// --end for stores that can use it, e.g. redis matches the prefix.
+func ScanBatches(ctx context.Context, c Client, startKey, prefix []byte, keyOnly bool, batchSize, limit int, fn func(kvs KVS) error) error {
+	opt := properties.NewProperties()
+	opt.Set(tcli.ScanOptStrictPrefix, "false")
+	opt.Set(tcli.ScanOptKeyOnly, strconv.FormatBool(keyOnly))
+	if end := utils.PrefixNextKey(prefix); len(prefix) > 0 && end != nil {
+		opt.Set(tcli.ScanOptEnd, utils.Bytes2StrLit(end))
+	}
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := batchSize
+		if limit > 0 && limit-total < n {
+			n = limit - total
+		}
+		if n <= 0 {
+			return nil
+		}
+		opt.Set(tcli.ScanOptLimit, strconv.Itoa(n))
+		kvs, cnt, err := c.Scan(utils.ContextWithProp(ctx, opt), startKey)
+		if err != nil {
+			return err
+		}
+		done := cnt < n
+		for i, kv := range kvs {
+			if !bytes.HasPrefix(kv.K, prefix) {
+				kvs, done = kvs[:i], true
+				break
+			}
+		}
+		if len(kvs) > 0 {
+			if err := fn(kvs); err != nil {
+				return err
+			}
+		}
+		if done || len(kvs) == 0 {
+			return nil
+		}
+		total += len(kvs)
+		startKey = utils.NextKey(kvs[len(kvs)-1].K)
+	}
+}
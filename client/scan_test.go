@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestScanBatches(t *testing.T) {
+	c := newMemClient()
+	var kvs []KV
+	for i := 0; i < 250; i++ {
+		kvs = append(kvs, KV{K: Key(fmt.Sprintf("sb_%03d", i)), V: Value("v")})
+	}
+	kvs = append(kvs, KV{K: Key("sc"), V: Value("v")})
+	if err := c.BatchPut(context.TODO(), kvs); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		start, prefix string
+		batchSize     int
+		limit         int
+		want, batches int
+	}{
+		{"sb_", "sb_", 100, 0, 250, 3},
+		{"sb_", "sb_", 100, 150, 150, 2},
+		{"sb_", "sb_", 50, 50, 50, 1},
+		{"sb_1", "sb_1", 100, 0, 100, 1},
+		{"sb_200", "sb_", 100, 0, 50, 1},
+		{"sb_", "", 100, 0, 251, 3},
+		{"nosb_", "nosb_", 100, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		var prefix []byte
+		if tt.prefix != "" {
+			prefix = []byte(tt.prefix)
+		}
+		got, batches := 0, 0
+		err := ScanBatches(context.TODO(), c, []byte(tt.start), prefix, true, tt.batchSize, tt.limit, func(kvs KVS) error {
+			got += len(kvs)
+			batches++
+			return nil
+		})
+		if err != nil || got != tt.want || batches != tt.batches {
+			t.Errorf("%+v: got %d keys in %d batches, %v", tt, got, batches, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	calls := 0
+	err := ScanBatches(ctx, c, []byte("sb_"), []byte("sb_"), true, 10, 0, func(kvs KVS) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if err != context.Canceled || calls != 1 {
+		t.Errorf("cancelled scan: %d calls, %v", calls, err)
+	}
+}
+
+func TestRangePrefix(t *testing.T) {
+	tests := []struct {
+		lower, upper string
+		nilUpper     bool
+		want         string
+	}{
+		{"user_", "user`", false, "user_"},
+		{"user_0100", "user`", false, "user_"},
+		{"user_0100\x00", "user`", false, "user_"},
+		{"user_01", "user_02", false, "user_01"},
+		{"user_01", "user_03", false, "user_0"},
+		{"a", "b", false, "a"},
+		{"a", "c", false, ""},
+		{"\xff\xff", "", true, ""},
+		{"user_", "", true, ""},
+		{"", "b", false, ""},
+	}
+	for _, tt := range tests {
+		var upper []byte
+		if !tt.nilUpper {
+			upper = []byte(tt.upper)
+		}
+		if got := rangePrefix([]byte(tt.lower), upper); string(got) != tt.want {
+			t.Errorf("[%q, %q): got %q, want %q", tt.lower, tt.upper, got, tt.want)
+		}
+	}
+}
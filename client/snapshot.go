@@ -0,0 +1,65 @@
+package client
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+// SnapshotClient is implemented by clients of a transactional TiKV
+// cluster, their reads can be pinned to a timestamp so that a sequence of
+// commands sees one consistent view of the data
+type SnapshotClient interface {
+	// SetSnapshotTS pins Get, BatchGet and Scan to ts, 0 unpins them
+	SetSnapshotTS(ts uint64) error
+	// SnapshotTS returns the pinned timestamp, 0 if reads are not pinned
+	SnapshotTS() uint64
+}
+
+func (c *txnkvClient) SetSnapshotTS(ts uint64) error {
+	atomic.StoreUint64(&c.snapshotTS, ts)
+	return nil
+}
+
+func (c *txnkvClient) SnapshotTS() uint64 {
+	return atomic.LoadUint64(&c.snapshotTS)
+}
+
+// beginRead starts the transaction of a read, at the pinned timestamp if
+// there is one
+func (c *txnkvClient) beginRead() (*tikv.KVTxn, error) {
+	if ts := c.SnapshotTS(); ts != 0 {
+		return c.txnClient.BeginWithOption(tikv.DefaultStartTSOption().SetStartTS(ts))
+	}
+	return c.txnClient.Begin()
+}
+
+// the timestamps of the primary and the standby clusters are unrelated, a
+// snapshot is only pinned on the primary
+func (c *failoverClient) SetSnapshotTS(ts uint64) error {
+	if ts != 0 && c.onStandby() != nil {
+		return errors.New("snapshots are not supported on the standby cluster")
+	}
+	sc, ok := c.primary.(SnapshotClient)
+	if !ok {
+		return errors.New("snapshots are only supported in txn mode")
+	}
+	return sc.SetSnapshotTS(ts)
+}
+
+func (c *failoverClient) SnapshotTS() uint64 {
+	if sc, ok := c.primary.(SnapshotClient); ok {
+		return sc.SnapshotTS()
+	}
+	return 0
+}
+
+// PinnedSnapshotTS returns the timestamp the reads of the global client are
+// pinned to, 0 if there is none
+func PinnedSnapshotTS() uint64 {
+	if sc, ok := GetTiKVClient().(SnapshotClient); ok {
+		return sc.SnapshotTS()
+	}
+	return 0
+}
@@ -10,25 +10,26 @@ import (
 
 	"github.com/c4pt0r/tcli"
 
-	"github.com/c4pt0r/log"
 	"github.com/tikv/client-go/v2/tikv"
 	pd "github.com/tikv/pd/client"
 )
 
-func newTxnKVClient(pdAddr []string) *txnkvClient {
+func newTxnKVClient(pdAddr []string) (*txnkvClient, error) {
 	client, err := tikv.NewTxnClient(pdAddr)
 	if err != nil {
-		log.F(err)
+		return nil, err
 	}
 	return &txnkvClient{
 		txnClient: client,
 		pdAddr:    pdAddr,
-	}
+	}, nil
 }
 
 type txnkvClient struct {
 	txnClient *tikv.KVStore
 	pdAddr    []string
+	// reads are pinned to it if not 0, see SnapshotClient
+	snapshotTS uint64
 }
 
 func (c *txnkvClient) Close() {
@@ -54,8 +55,12 @@ func (c *txnkvClient) GetStores() ([]StoreInfo, error) {
 	for _, store := range stores {
 		labels := store.GetLabels()
 		var strLabels []string
+		var zone string
 		for _, label := range labels {
 			strLabels = append(strLabels, fmt.Sprintf("%s=%s", label.Key, label.Value))
+			if label.Key == "zone" {
+				zone = label.Value
+			}
 		}
 		ret = append(ret, StoreInfo{
 			ID:            fmt.Sprintf("%d", store.GetId()),
@@ -63,6 +68,7 @@ func (c *txnkvClient) GetStores() ([]StoreInfo, error) {
 			Addr:          store.GetAddress(),
 			State:         store.GetState().String(),
 			StatusAddress: store.GetStatusAddress(),
+			Zone:          zone,
 			Labels:        strings.Join(strLabels, ","),
 		})
 	}
@@ -93,20 +99,33 @@ func (c *txnkvClient) Put(ctx context.Context, kv KV) error {
 
 func (c *txnkvClient) Scan(ctx context.Context, startKey []byte) (KVS, int, error) {
 	scanOpts := utils.PropFromContext(ctx)
-	tx, err := c.txnClient.Begin()
+	tx, err := c.beginRead()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	strictPrefix := scanOpts.GetBool(tcli.ScanOptStrictPrefix, false)
 	countOnly := scanOpts.GetBool(tcli.ScanOptCountOnly, false)
 	keyOnly := scanOpts.GetBool(tcli.ScanOptKeyOnly, false)
+	// counting never needs values
 	if keyOnly || countOnly {
-		tx.GetSnapshot().SetKeyOnly(keyOnly)
+		tx.GetSnapshot().SetKeyOnly(true)
 	}
 	// count only mode will ignore this
 	limit := scanOpts.GetInt(tcli.ScanOptLimit, 100)
-	it, err := tx.Iter(startKey, nil)
+	lower, upper, reverse, err := scanBounds(scanOpts, startKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	var it tikv.Iterator
+	if reverse {
+		if upper == nil {
+			return nil, 0, errReverseScanNoUpperBound
+		}
+		it, err = tx.IterReverse(upper)
+	} else {
+		// bound the iterator, so it doesn't prefetch keys after the range
+		it, err = tx.Iter(lower, upper)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -119,7 +138,7 @@ func (c *txnkvClient) Scan(ctx context.Context, startKey []byte) (KVS, int, erro
 		if !countOnly && limit == 0 {
 			break
 		}
-		if strictPrefix && !bytes.HasPrefix(it.Key(), startKey) {
+		if afterScanRange(it.Key(), lower, upper, reverse) {
 			break
 		}
 		// count only will not use limit
@@ -153,7 +172,7 @@ func (c *txnkvClient) BatchPut(ctx context.Context, kvs []KV) error {
 }
 
 func (c *txnkvClient) Get(ctx context.Context, k Key) (KV, error) {
-	tx, err := c.txnClient.Begin()
+	tx, err := c.beginRead()
 	if err != nil {
 		return KV{}, err
 	}
@@ -164,6 +183,28 @@ func (c *txnkvClient) Get(ctx context.Context, k Key) (KV, error) {
 	return KV{K: k, V: v}, nil
 }
 
+func (c *txnkvClient) BatchGet(ctx context.Context, keys []Key) (KVS, error) {
+	tx, err := c.beginRead()
+	if err != nil {
+		return nil, err
+	}
+	ks := make([][]byte, len(keys))
+	for i, k := range keys {
+		ks[i] = k
+	}
+	values, err := tx.BatchGet(ctx, ks)
+	if err != nil {
+		return nil, err
+	}
+	var ret KVS
+	for _, k := range keys {
+		if v, ok := values[string(k)]; ok {
+			ret = append(ret, KV{K: k, V: v})
+		}
+	}
+	return ret, nil
+}
+
 func (c *txnkvClient) Delete(ctx context.Context, k Key) error {
 	tx, err := c.txnClient.Begin()
 	if err != nil {
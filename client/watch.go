@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/c4pt0r/tcli/utils"
+)
+
+const (
+	WatchEventPut    = "PUT"
+	WatchEventUpdate = "UPDATE"
+	WatchEventDelete = "DELETE"
+)
+
+type WatchEvent struct {
+	Type string
+	KV   KV
+}
+
+// WatchClient is implemented by clients with a native change feed
+type WatchClient interface {
+	Watch(ctx context.Context, prefix Key, cb func(WatchEvent)) error
+}
+
+// watchScanBatchSize is the scan batch size of polling watches
+var watchScanBatchSize = 1000
+
+// WatchPrefix calls cb for every change under prefix until ctx is done.
+// Clients without a native change feed are polled every interval and the
+// prefix is diffed against the previous scan, so changes between two polls
+// are merged and an UPDATE means "value differs from the last poll".
+func WatchPrefix(ctx context.Context, c Client, prefix Key, interval time.Duration, cb func(WatchEvent)) error {
+	if interval <= 0 {
+		return utils.NewParseError("invalid watch interval: %v, should be positive", interval)
+	}
+	if wc, ok := c.(WatchClient); ok {
+		return wc.Watch(ctx, prefix, cb)
+	}
+
+	last, err := scanPrefixToMap(ctx, c, prefix)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := scanPrefixToMap(ctx, c, prefix)
+		if err != nil {
+			return err
+		}
+		for k, v := range cur {
+			old, ok := last[k]
+			switch {
+			case !ok:
+				cb(WatchEvent{Type: WatchEventPut, KV: KV{K: Key(k), V: v}})
+			case !bytes.Equal(old, v):
+				cb(WatchEvent{Type: WatchEventUpdate, KV: KV{K: Key(k), V: v}})
+			}
+		}
+		for k := range last {
+			if _, ok := cur[k]; !ok {
+				cb(WatchEvent{Type: WatchEventDelete, KV: KV{K: Key(k)}})
+			}
+		}
+		last = cur
+	}
+}
+
+func scanPrefixToMap(ctx context.Context, c Client, prefix Key) (map[string]Value, error) {
+	ret := make(map[string]Value)
+	err := ScanBatches(ctx, c, prefix, prefix, false, watchScanBatchSize, 0, func(kvs KVS) error {
+		for _, kv := range kvs {
+			ret[string(kv.K)] = kv.V
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
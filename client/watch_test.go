@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestScanPrefixToMapPaging(t *testing.T) {
+	c := newMemClient()
+	var kvs []KV
+	for i := 0; i < 2*watchScanBatchSize+10; i++ {
+		kvs = append(kvs, KV{K: Key(fmt.Sprintf("job_%05d", i)), V: Value("v")})
+	}
+	kvs = append(kvs, KV{K: Key("jobs"), V: Value("v")})
+	if err := c.BatchPut(context.TODO(), kvs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{"job_", 2*watchScanBatchSize + 10},
+		{"job_0000", 10},
+		{"job", 2*watchScanBatchSize + 11},
+		{"nojob", 0},
+	}
+	for _, tt := range tests {
+		m, err := scanPrefixToMap(context.TODO(), c, Key(tt.prefix))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m) != tt.want {
+			t.Errorf("prefix %q: got %d keys, want %d", tt.prefix, len(m), tt.want)
+		}
+	}
+}
+
+func TestWatchPrefixInterval(t *testing.T) {
+	c := newMemClient()
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := WatchPrefix(context.TODO(), c, Key("job_"), interval, func(WatchEvent) {})
+		if err == nil {
+			t.Errorf("interval %v: expected an error", interval)
+		}
+	}
+}
@@ -6,6 +6,11 @@ var (
 	ScanOptCountOnly    string = "count-only"
 	ScanOptLimit        string = "limit"
 	ScanOptStrictPrefix string = "strict-prefix"
+	ScanOptReverse      string = "reverse"
+	ScanOptEnd          string = "end"
+	ScanOptFlatten      string = "flatten"
+	// alias of key-only
+	ScanOptKeysOnly string = "keys-only"
 )
 
 // for completer to work, keyword list
@@ -14,6 +19,10 @@ var ScanOptsKeywordList = []string{
 	ScanOptCountOnly,
 	ScanOptLimit,
 	ScanOptStrictPrefix,
+	ScanOptReverse,
+	ScanOptEnd,
+	ScanOptKeysOnly,
+	ScanOptFlatten,
 }
 
 ///////////////////// end of scan options ///////////////
@@ -24,6 +33,9 @@ var (
 	DeleteOptBatchSize  string = "batch-size"
 	DeleteOptLimit      string = "limit"
 	DeleteOptYes        string = "yes"
+	DeleteOptDryRun     string = "dry-run"
+	DeleteOptSample     string = "sample"
+	DeleteOptBackground string = "bg"
 )
 
 var DeleteOptsKeywordList = []string{
@@ -31,30 +43,214 @@ var DeleteOptsKeywordList = []string{
 	DeleteOptBatchSize,
 	DeleteOptLimit,
 	DeleteOptYes,
+	DeleteOptDryRun,
+	DeleteOptSample,
+	DeleteOptBackground,
 }
 
 //////////////// end of del/delp/delall options ////////
 
 ///////////////// loadcsv options //////////////////////
 var (
-	LoadFileOptBatchSize string = "batch-size"
-	LoadFileoptSkipRows  string = "skip-rows"
+	LoadFileOptBatchSize  string = "batch-size"
+	LoadFileoptSkipRows   string = "skip-rows"
+	LoadFileOptResume     string = "resume"
+	LoadFileOptBackground string = "bg"
 )
 
 var LoadFileOptsKeywordList = []string{
 	LoadFileOptBatchSize,
 	LoadFileoptSkipRows,
+	LoadFileOptResume,
+	LoadFileOptBackground,
 }
 
 //////////////// end of loadcsv options ///////////////
 
 ///////////////// backup options /////////////////////
 var (
-	BackupOptBatchSize string = "batch-size"
+	BackupOptBatchSize  string = "batch-size"
+	BackupOptResume     string = "resume"
+	BackupOptBackground string = "bg"
 )
 
 var BackupOptsKeywordList = []string{
 	BackupOptBatchSize,
+	BackupOptResume,
+	BackupOptBackground,
 }
 
 //////////////// end of backup options ///////////////
+
+///////////////// watchcdc options ///////////////////
+var (
+	WatchOptInterval string = "interval"
+)
+
+var WatchOptsKeywordList = []string{
+	WatchOptInterval,
+}
+
+//////////////// end of watchcdc options /////////////
+
+///////////////// histogram options //////////////////
+var (
+	HistogramOptLimit     string = "limit"
+	HistogramOptBatchSize string = "batch-size"
+	HistogramOptTop       string = "top"
+)
+
+var HistogramOptsKeywordList = []string{
+	HistogramOptLimit,
+	HistogramOptBatchSize,
+	HistogramOptTop,
+}
+
+//////////////// end of histogram options /////////////
+
+///////////////// analyze options ////////////////////
+var (
+	AnalyzeOptSample    string = "sample"
+	AnalyzeOptExamples  string = "examples"
+	AnalyzeOptBatchSize string = "batch-size"
+)
+
+var AnalyzeOptsKeywordList = []string{
+	AnalyzeOptSample,
+	AnalyzeOptExamples,
+	AnalyzeOptBatchSize,
+}
+
+//////////////// end of analyze options ///////////////
+
+///////////////// dupes options //////////////////////
+var (
+	DupesOptLimit      string = "limit"
+	DupesOptBatchSize  string = "batch-size"
+	DupesOptTop        string = "top"
+	DupesOptExamples   string = "examples"
+	DupesOptMinSize    string = "min-size"
+	DupesOptMaxDigests string = "max-digests"
+)
+
+var DupesOptsKeywordList = []string{
+	DupesOptLimit,
+	DupesOptBatchSize,
+	DupesOptTop,
+	DupesOptExamples,
+	DupesOptMinSize,
+	DupesOptMaxDigests,
+}
+
+//////////////// end of dupes options /////////////////
+
+///////////////// browse options /////////////////////
+var (
+	BrowseOptLimit string = "limit"
+)
+
+var BrowseOptsKeywordList = []string{
+	BrowseOptLimit,
+}
+
+//////////////// end of browse options ////////////////
+
+///////////////// estimate options ///////////////////
+var (
+	EstimateOptEnd string = "end"
+	EstimateOptBy  string = "by"
+)
+
+var EstimateOptsKeywordList = []string{
+	EstimateOptEnd,
+	EstimateOptBy,
+}
+
+//////////////// end of estimate options //////////////
+
+///////////////// get options ///////////////////
+var (
+	GetOptRaw string = "raw"
+)
+
+var GetOptsKeywordList = []string{
+	GetOptRaw,
+}
+
+//////////////// end of get options //////////////
+
+///////////////// puts options ///////////////////
+var (
+	PutsOptBatchSize string = "batch-size"
+)
+
+var PutsOptsKeywordList = []string{
+	PutsOptBatchSize,
+}
+
+//////////////// end of puts options //////////////
+
+///////////////// codec options ///////////////////
+var (
+	CodecOptVar string = "var"
+)
+
+var CodecOptsKeywordList = []string{
+	CodecOptVar,
+}
+
+//////////////// end of codec options //////////////
+
+///////////////// verify options ///////////////////
+var (
+	VerifyOptEnd       string = "end"
+	VerifyOptBatchSize string = "batch-size"
+)
+
+var VerifyOptsKeywordList = []string{
+	VerifyOptEnd,
+	VerifyOptBatchSize,
+}
+
+//////////////// end of verify options //////////////
+
+///////////////// sample options ///////////////////
+var (
+	SampleOptBatchSize string = "batch-size"
+	SampleOptSeed      string = "seed"
+	SampleOptFlatten   string = "flatten"
+)
+
+var SampleOptsKeywordList = []string{
+	SampleOptBatchSize,
+	SampleOptSeed,
+	SampleOptFlatten,
+}
+
+//////////////// end of sample options //////////////
+
+///////////////// diff options ///////////////////
+var (
+	DiffOptEnd       string = "end"
+	DiffOptBatchSize string = "batch-size"
+)
+
+var DiffOptsKeywordList = []string{
+	DiffOptEnd,
+	DiffOptBatchSize,
+}
+
+//////////////// end of diff options //////////////
+
+///////////////// ping options ///////////////////
+var (
+	PingOptCount   string = "count"
+	PingOptTimeout string = "timeout"
+)
+
+var PingOptsKeywordList = []string{
+	PingOptCount,
+	PingOptTimeout,
+}
+
+//////////////// end of ping options //////////////
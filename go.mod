@@ -7,16 +7,26 @@ require (
 	github.com/abiosoft/ishell v2.0.0+incompatible
 	github.com/abiosoft/readline v0.0.0-20180607040430-155bce2042db // indirect
 	github.com/c4pt0r/log v0.0.0-20211004143616-aa6380016a47
+	github.com/dgraph-io/badger/v3 v3.2103.2
 	github.com/fatih/color v1.12.0
-	github.com/flynn-archive/go-shlex v0.0.0-20150515145356-3f9db97f8568 // indirect
+	github.com/flynn-archive/go-shlex v0.0.0-20150515145356-3f9db97f8568
+	github.com/golang/protobuf v1.3.4
+	github.com/gomodule/redigo v1.8.9
 	github.com/magiconair/properties v1.8.0
 	github.com/manifoldco/promptui v0.8.0
+	github.com/mattn/go-isatty v0.0.12
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/pingcap/go-ycsb v0.0.0-20210727125954-0c816a248fc3
+	github.com/pingcap/kvproto v0.0.0-20210531063847-f42e582bf0bb
 	github.com/pingcap/log v0.0.0-20210317133921-96f4fcab92a4
-	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.5.1
 	github.com/sirupsen/logrus v1.8.1 // indirect
+	github.com/syndtr/goleveldb v1.0.1-0.20190318030020-c3a204f8e965
 	github.com/tikv/client-go/v2 v2.0.0-alpha.0.20210706041121-6ca00989ddb4
 	github.com/tikv/pd v1.1.0-beta.0.20210323121136-78679e5e209d
+	go.etcd.io/bbolt v1.3.6
+	go.etcd.io/etcd v0.5.0-alpha.5.0.20200824191128-ae9734ed278b
 	go.uber.org/atomic v1.7.0
+	go.uber.org/zap v1.16.0
+	google.golang.org/grpc v1.27.1
 )
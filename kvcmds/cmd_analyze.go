@@ -0,0 +1,316 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type AnalyzeCmd struct{}
+
+var _ tcli.Cmd = AnalyzeCmd{}
+
+func (c AnalyzeCmd) Name() string    { return "analyze" }
+func (c AnalyzeCmd) Alias() []string { return []string{"analyze"} }
+func (c AnalyzeCmd) Help() string {
+	return `analyze the structure of keys, use "analyze --help" for more details`
+}
+
+func (c AnalyzeCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	analyze patterns <key prefix | *> <options>
+Options:
+	--sample=<n>, analyze the first n keys, default 10000
+	--examples=<n>, example keys per pattern, default 3
+	--batch-size=<size>, default 1000
+Description:
+	Keys are split into segments by the delimiters : / _ - . | # , ; = and
+	space, and by runs of binary bytes. Segments with few distinct values are
+	shown as is, others as placeholders:
+		{num:8}  decimal digits, fixed width 8 ({num} if the width varies)
+		{hex:32} hex digits
+		{str}    other printable text
+		{bin:8}  binary bytes
+Examples:
+	analyze patterns "user_"
+	analyze patterns * --sample=100000
+`
+	return s
+}
+
+type segmentClass int
+
+const (
+	segDelim segmentClass = iota
+	segNum
+	segHex
+	segStr
+	segBin
+)
+
+func (c segmentClass) String() string {
+	switch c {
+	case segNum:
+		return "num"
+	case segHex:
+		return "hex"
+	case segStr:
+		return "str"
+	case segBin:
+		return "bin"
+	}
+	return "delim"
+}
+
+type keySegment struct {
+	class segmentClass
+	value string
+}
+
+func isKeyDelim(ch byte) bool {
+	return strings.IndexByte(":/_-.|#,;= ", ch) >= 0
+}
+
+func isPrintable(ch byte) bool {
+	return ch >= 0x20 && ch <= 0x7e
+}
+
+func classifyText(s string) segmentClass {
+	digits, hexLetters := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch >= '0' && ch <= '9':
+			digits++
+		case ch >= 'a' && ch <= 'f', ch >= 'A' && ch <= 'F':
+			hexLetters++
+		}
+	}
+	switch {
+	case digits == len(s):
+		return segNum
+	// short words like "face" or "bad" are more likely text than hex
+	case digits+hexLetters == len(s) && digits > 0 && len(s) >= 8:
+		return segHex
+	}
+	return segStr
+}
+
+// splitKey splits a key into delimiters, printable segments and runs of
+// binary bytes
+func splitKey(k []byte) []keySegment {
+	var segs []keySegment
+	for i := 0; i < len(k); {
+		j := i + 1
+		switch {
+		case !isPrintable(k[i]):
+			for j < len(k) && !isPrintable(k[j]) {
+				j++
+			}
+			segs = append(segs, keySegment{segBin, string(k[i:j])})
+		case isKeyDelim(k[i]):
+			segs = append(segs, keySegment{segDelim, string(k[i:j])})
+		default:
+			for j < len(k) && isPrintable(k[j]) && !isKeyDelim(k[j]) {
+				j++
+			}
+			segs = append(segs, keySegment{classifyText(string(k[i:j])), string(k[i:j])})
+		}
+		i = j
+	}
+	return segs
+}
+
+// shapeOf is the grouping signature of a key: delimiters as is, and the
+// class of every other segment
+func shapeOf(segs []keySegment) string {
+	var sb strings.Builder
+	for _, seg := range segs {
+		if seg.class == segDelim {
+			sb.WriteString(seg.value)
+		} else {
+			sb.WriteString("\x00" + seg.class.String() + "\x00")
+		}
+	}
+	return sb.String()
+}
+
+// a segment position with no more than maxLiteralValues distinct values
+// (and at most one per literalRatio keys) is part of the pattern
+const (
+	maxLiteralValues = 16
+	literalRatio     = 10
+)
+
+type keyPattern struct {
+	pattern  string
+	count    int
+	examples [][]byte
+}
+
+type sampledKey struct {
+	raw  []byte
+	segs []keySegment
+}
+
+func renderSegment(seg keySegment) string {
+	if seg.class == segBin {
+		return utils.Bytes2StrLit([]byte(seg.value))
+	}
+	return seg.value
+}
+
+func placeholder(class segmentClass, minLen, maxLen int) string {
+	if class == segStr {
+		return "{str}"
+	}
+	if minLen == maxLen {
+		return fmt.Sprintf("{%s:%d}", class, minLen)
+	}
+	return fmt.Sprintf("{%s}", class)
+}
+
+// patternsOf groups keys of the same shape into patterns
+func patternsOf(keys []sampledKey, maxExamples int) []*keyPattern {
+	n := len(keys[0].segs)
+	literal := make([]bool, n)
+	minLen := make([]int, n)
+	maxLen := make([]int, n)
+	for i := 0; i < n; i++ {
+		if keys[0].segs[i].class == segDelim {
+			literal[i] = true
+			continue
+		}
+		distinct := make(map[string]struct{})
+		minLen[i], maxLen[i] = len(keys[0].segs[i].value), 0
+		for _, k := range keys {
+			v := k.segs[i].value
+			if len(distinct) <= maxLiteralValues {
+				distinct[v] = struct{}{}
+			}
+			if len(v) < minLen[i] {
+				minLen[i] = len(v)
+			}
+			if len(v) > maxLen[i] {
+				maxLen[i] = len(v)
+			}
+		}
+		literal[i] = len(distinct) == 1 ||
+			(len(distinct) <= maxLiteralValues && len(distinct)*literalRatio <= len(keys))
+	}
+
+	patterns := make(map[string]*keyPattern)
+	var order []*keyPattern
+	for _, k := range keys {
+		var sb strings.Builder
+		for i, seg := range k.segs {
+			if literal[i] {
+				sb.WriteString(renderSegment(seg))
+			} else {
+				sb.WriteString(placeholder(seg.class, minLen[i], maxLen[i]))
+			}
+		}
+		p, ok := patterns[sb.String()]
+		if !ok {
+			p = &keyPattern{pattern: sb.String()}
+			patterns[p.pattern] = p
+			order = append(order, p)
+		}
+		p.count++
+		if len(p.examples) < maxExamples {
+			p.examples = append(p.examples, k.raw)
+		}
+	}
+	return order
+}
+
+func analyzeKeyPatterns(keys []sampledKey, maxExamples int) []*keyPattern {
+	shapes := make(map[string][]sampledKey)
+	var shapeOrder []string
+	for _, k := range keys {
+		shape := shapeOf(k.segs)
+		if _, ok := shapes[shape]; !ok {
+			shapeOrder = append(shapeOrder, shape)
+		}
+		shapes[shape] = append(shapes[shape], k)
+	}
+	var ret []*keyPattern
+	for _, shape := range shapeOrder {
+		ret = append(ret, patternsOf(shapes[shape], maxExamples)...)
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].count > ret[j].count })
+	return ret
+}
+
+func (c AnalyzeCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			if ic.Args[0] != "patterns" {
+				return errors.New("unknown analyze type, should be patterns")
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[2])
+			if err != nil {
+				return err
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 2 {
+				if err := utils.SetOptByString(ic.Args[2:], opt); err != nil {
+					return err
+				}
+			}
+			sample := opt.GetInt(tcli.AnalyzeOptSample, 10000)
+			batchSize := opt.GetInt(tcli.AnalyzeOptBatchSize, 1000)
+			if sample <= 0 || batchSize <= 0 {
+				return errors.New("sample and batch-size should be greater than 0")
+			}
+
+			var keys []sampledKey
+			err = scanPrefixBatches(prefix, true, batchSize, sample, func(kvs client.KVS) error {
+				for _, kv := range kvs {
+					keys = append(keys, sampledKey{raw: kv.K, segs: splitKey(kv.K)})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 {
+				utils.Print("No keys found")
+				return nil
+			}
+
+			patterns := analyzeKeyPatterns(keys, opt.GetInt(tcli.AnalyzeOptExamples, 3))
+			data := [][]string{{"Pattern", "Keys", "Percent", "Examples"}}
+			for _, p := range patterns {
+				var examples []string
+				for _, k := range p.examples {
+					examples = append(examples, utils.Bytes2ReadableStrLit(k))
+				}
+				data = append(data, []string{
+					p.pattern,
+					strconv.Itoa(p.count),
+					fmt.Sprintf("%.2f%%", float64(p.count)*100/float64(len(keys))),
+					strings.Join(examples, " "),
+				})
+			}
+			utils.PrintTable(data)
+			fmt.Fprintf(os.Stderr, "%d keys sampled, %d patterns\n", len(keys), len(patterns))
+			return nil
+		})
+	}
+}
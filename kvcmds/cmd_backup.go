@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/c4pt0r/tcli"
@@ -31,6 +33,11 @@ Usage:
 	backup <prefix> <outfile> <opts>
 Options:
 	--batch-size=<size>, default 1000
+	--resume, continue an interrupted backup to <outfile>
+	--bg, back up in the background, see "jobs"
+Description:
+	The progress is recorded in <outfile>.manifest after every batch, with
+	the key count and checksum of the kv pairs written, see "manifest".
 Example:
 	# backup all kvs with prefix "t_" to csv file
 	backup "t_" backup.csv --batch-size=5000
@@ -38,6 +45,9 @@ Example:
 	# backup all kvs to csv file
 	backup * backup.csv
 	backup $head  backup.csv
+
+	# continue after an interruption
+	backup "t_" backup.csv --resume
 `)
 	return buf.String()
 }
@@ -54,6 +64,105 @@ func writeKvsToCsvFile(w *csv.Writer, kvs client.KVS) error {
 	return nil
 }
 
+// openBackupFile creates outputFile with its manifest, or reopens both to
+// resume an interrupted backup. Rows written after the last batch recorded
+// by the manifest are dropped.
+func openBackupFile(outputFile string, settings map[string]string, resume bool) (*os.File, *utils.JobManifest, error) {
+	if !resume {
+		_, err := os.Stat(outputFile)
+		if !os.IsNotExist(err) {
+			return nil, nil, errors.New("Backup file already exists")
+		}
+		fp, err := os.Create(outputFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fp, utils.NewJobManifest("backup", outputFile, settings), nil
+	}
+	manifest, err := utils.LoadJobManifest(outputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("no manifest found for %s, can't resume", outputFile)
+	}
+	if err := manifest.CheckResume("backup", settings); err != nil {
+		return nil, nil, err
+	}
+	if err := os.Truncate(outputFile, manifest.FileSize); err != nil {
+		return nil, nil, err
+	}
+	fp, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fp, manifest, nil
+}
+
+// run writes the kv pairs with prefix to fp, a nil job means the backup
+// runs in the foreground
+func (c BackupCmd) run(ctx context.Context, job *utils.Job, fp *os.File, manifest *utils.JobManifest,
+	prefix []byte, batchSize int, resume bool) error {
+	defer fp.Close()
+	csvWriter := csv.NewWriter(fp)
+	defer csvWriter.Flush()
+	var from []byte
+	if resume {
+		lastKey, err := manifest.LastKey()
+		if err != nil {
+			return err
+		}
+		if lastKey != nil {
+			from = utils.NextKey(lastKey)
+		}
+		job.Print(fmt.Sprintf("Resume backup after %d keys", manifest.Keys))
+	} else {
+		// Write first line
+		csvWriter.Write([]string{"Key", "Value"})
+		csvWriter.Flush()
+		var err error
+		if manifest.FileSize, err = fp.Seek(0, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+	manifest.StartRun()
+	if err := manifest.Save(); err != nil {
+		return err
+	}
+
+	err := scanPrefixBatchesFrom(prefix, from, false, batchSize, 0, func(kvs client.KVS) error {
+		// write file
+		if err := writeKvsToCsvFile(csvWriter, kvs); err != nil {
+			return err
+		}
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+		// the manifest is only saved once the batch is on disk
+		if err := fp.Sync(); err != nil {
+			return err
+		}
+		size, err := fp.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			manifest.AddPair(kv.K, kv.V)
+		}
+		manifest.FileSize = size
+		if err := manifest.Save(); err != nil {
+			return err
+		}
+		job.Print("Write a batch, batch size:", len(kvs), "Last key:", kvs[len(kvs)-1].K)
+		return job.Done(ctx, len(kvs))
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Done = true
+	return manifest.Save()
+}
+
 func (c BackupCmd) Handler() func(ctx context.Context) {
 	return func(ctx context.Context) {
 		utils.OutputWithElapse(func() error {
@@ -67,48 +176,31 @@ func (c BackupCmd) Handler() func(ctx context.Context) {
 				return err
 			}
 			outputFile := ic.Args[1]
-			_, err = os.Stat(outputFile)
-			if !os.IsNotExist(err) {
-				return errors.New("Backup file already exists")
-			}
-			fp, err := os.Create(outputFile)
-			if err != nil {
-				return err
-			}
-			csvWriter := csv.NewWriter(fp)
-			defer csvWriter.Flush()
-			// Write first line
-			csvWriter.Write([]string{"Key", "Value"})
-
 			opt := properties.NewProperties()
-			if len(ic.Args) > 1 {
-				err := utils.SetOptByString(ic.Args[1:], opt)
+			if len(ic.Args) > 2 {
+				err := utils.SetOptByString(ic.Args[2:], opt)
 				if err != nil {
 					return err
 				}
 			}
-			opt.Set(tcli.ScanOptLimit, opt.GetString(tcli.BackupOptBatchSize, "1000"))
-			if bytes.Compare(prefix, []byte("\x00")) != 0 && string(prefix) != "*" {
-				opt.Set(tcli.ScanOptStrictPrefix, "true")
+			batchSize := opt.GetInt(tcli.BackupOptBatchSize, 1000)
+			if batchSize <= 0 {
+				return utils.NewParseError("batch size should be positive")
+			}
+
+			settings := map[string]string{
+				"prefix":  utils.Bytes2ReadableStrLit(prefix),
+				"cluster": client.GetTiKVClient().GetClusterID(),
 			}
-			kvs, cnt, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), opt), prefix)
+			resume := opt.GetBool(tcli.BackupOptResume, false)
+			fp, manifest, err := openBackupFile(outputFile, settings, resume)
 			if err != nil {
 				return err
 			}
-			for cnt > 0 {
-				// write file
-				if err := writeKvsToCsvFile(csvWriter, kvs); err != nil {
-					return err
-				}
-				lastKey := utils.NextKey(kvs[len(kvs)-1].K)
-				utils.Print("Write a batch, batch size:", cnt, "Last key:", kvs[len(kvs)-1].K)
-				// run next batch
-				kvs, cnt, err = client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), opt), lastKey)
-				if err != nil {
-					return err
-				}
+			run := func(ctx context.Context, job *utils.Job) error {
+				return c.run(ctx, job, fp, manifest, prefix, batchSize, resume)
 			}
-			return nil
+			return runBulk(ic, opt.GetBool(tcli.BackupOptBackground, false), run)
 		})
 	}
 }
@@ -102,7 +102,7 @@ func NewYcsbBench(pdAddr string) BenchWorkload {
 
 func (y *YcsbBench) Name() string { return "ycsb" }
 func (y *YcsbBench) Run(ctx context.Context) error {
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	// Ctrl-C to break
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
@@ -0,0 +1,267 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+	"github.com/manifoldco/promptui"
+)
+
+type BrowseCmd struct{}
+
+var _ tcli.Cmd = BrowseCmd{}
+
+func (c BrowseCmd) Name() string    { return "browse" }
+func (c BrowseCmd) Alias() []string { return []string{"browse"} }
+func (c BrowseCmd) Help() string {
+	return `browse keys interactively, use "browse --help" for more details`
+}
+
+func (c BrowseCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	browse <key prefix | *> <options>
+Options:
+	--limit=<limit>, max keys to load, default 1000
+Keys:
+	up / down / left / right    move and page through keys
+	/                           filter keys, matches substrings case-insensitively
+	enter                       view, edit or delete the selected key
+Notes:
+	single-line values are edited inline, multi-line ones such as pretty JSON
+	in $VISUAL or $EDITOR (vim by default), binary values can't be edited.
+	ctrl-c                      quit
+Examples:
+	browse "user_"
+	browse * --limit=10000
+`
+	return s
+}
+
+// browseLines is the height of the key list and of the value preview
+const browseLines = 15
+
+type browseEntry struct {
+	kv      client.KV
+	Key     string
+	Preview string
+}
+
+func newBrowseEntry(kv client.KV) *browseEntry {
+	e := &browseEntry{kv: kv, Key: utils.Bytes2ReadableStrLit(kv.K)}
+	e.Preview = truncateLines(formatValue(kv.V), browseLines)
+	return e
+}
+
+// formatValue pretty-prints JSON values and hex-dumps binary ones
+func formatValue(v []byte) string {
+	var buf bytes.Buffer
+	if json.Valid(v) && json.Indent(&buf, v, "", "  ") == nil {
+		return buf.String()
+	}
+	for _, ch := range v {
+		if (ch < 0x20 || ch > 0x7e) && ch != '\n' && ch != '\t' {
+			return hex.Dump(v)
+		}
+	}
+	return string(v)
+}
+
+func truncateLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append(lines[:n], fmt.Sprintf("... (%d more lines)", len(lines)-n))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c BrowseCmd) loadEntries(prefix []byte, limit int) ([]*browseEntry, error) {
+	var entries []*browseEntry
+	err := scanPrefixBatches(prefix, false, 1000, limit, func(kvs client.KVS) error {
+		for _, kv := range kvs {
+			entries = append(entries, newBrowseEntry(kv))
+		}
+		return nil
+	})
+	return entries, err
+}
+
+const (
+	editInline = iota
+	editInEditor
+	editRefused
+)
+
+// editMode tells how v can be edited without corrupting it: printable
+// single-line text inline, multi-line text such as pretty JSON in $EDITOR,
+// binary values not at all
+func editMode(v []byte) int {
+	if !utf8.Valid(v) {
+		return editRefused
+	}
+	mode := editInline
+	for _, r := range string(v) {
+		switch {
+		case r == '\n' || r == '\t':
+			mode = editInEditor
+		case !unicode.IsPrint(r):
+			return editRefused
+		}
+	}
+	return mode
+}
+
+// editValue lets the user edit v, changed is false if it was cancelled or
+// left as it was
+func editValue(key string, v []byte) ([]byte, bool, error) {
+	var edited string
+	switch editMode(v) {
+	case editRefused:
+		return nil, false, fmt.Errorf("%s has a binary value, use put with a hex literal to change it", key)
+	case editInline:
+		prompt := promptui.Prompt{
+			Label:     "Value",
+			Default:   string(v),
+			AllowEdit: true,
+		}
+		var err error
+		if edited, err = prompt.Run(); err != nil {
+			return nil, false, nil
+		}
+	case editInEditor:
+		prompt := &survey.Editor{
+			Message:       "Edit " + key,
+			Default:       string(v),
+			HideDefault:   true,
+			AppendDefault: true,
+			FileName:      "tcli-value-*.txt",
+		}
+		if err := survey.AskOne(prompt, &edited); err != nil {
+			return nil, false, nil
+		}
+		// editors end the file with a newline
+		if !bytes.HasSuffix(v, []byte("\n")) {
+			edited = strings.TrimSuffix(edited, "\n")
+		}
+	}
+	if edited == string(v) {
+		return nil, false, nil
+	}
+	return []byte(edited), true, nil
+}
+
+// action runs the action chosen for e, returns false if e was deleted
+func (c BrowseCmd) action(e *browseEntry) (bool, error) {
+	menu := promptui.Select{
+		Label: e.Key,
+		Items: []string{"view", "edit", "delete", "back"},
+	}
+	_, choice, err := menu.Run()
+	if err != nil {
+		return true, nil
+	}
+	switch choice {
+	case "view":
+		utils.Print(formatValue(e.kv.V))
+	case "edit":
+		v, changed, err := editValue(e.Key, e.kv.V)
+		if err != nil || !changed {
+			return true, err
+		}
+		kv := client.KV{K: e.kv.K, V: v}
+		if err := client.GetTiKVClient().Put(context.TODO(), kv); err != nil {
+			return true, err
+		}
+		*e = *newBrowseEntry(kv)
+	case "delete":
+		if utils.AskYesNo(fmt.Sprintf("Delete %s?", e.Key), "no") != 1 {
+			return true, nil
+		}
+		if err := client.GetTiKVClient().Delete(context.TODO(), e.kv.K); err != nil {
+			return true, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func (c BrowseCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 1 {
+				if err := utils.SetOptByString(ic.Args[1:], opt); err != nil {
+					return err
+				}
+			}
+			entries, err := c.loadEntries(prefix, opt.GetInt(tcli.BrowseOptLimit, 1000))
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return errors.New("no keys found")
+			}
+
+			cursor, scroll := 0, 0
+			for len(entries) > 0 {
+				list := promptui.Select{
+					Label: fmt.Sprintf("%d keys", len(entries)),
+					Items: entries,
+					Size:  browseLines,
+					Templates: &promptui.SelectTemplates{
+						Label:    "{{ . }}",
+						Active:   "▸ {{ .Key | cyan }}",
+						Inactive: "  {{ .Key }}",
+						Selected: "{{ .Key }}",
+						Details:  "--------- Value ----------\n{{ .Preview }}",
+					},
+					Searcher: func(input string, index int) bool {
+						return strings.Contains(strings.ToLower(entries[index].Key), strings.ToLower(input))
+					},
+				}
+				idx, _, err := list.RunCursorAt(cursor, scroll)
+				if err != nil {
+					// ctrl-c / ctrl-d quits the browser
+					return nil
+				}
+				cursor, scroll = idx, list.ScrollPosition()
+				kept, err := c.action(entries[idx])
+				if err != nil {
+					return err
+				}
+				if !kept {
+					entries = append(entries[:idx], entries[idx+1:]...)
+					if cursor >= len(entries) && cursor > 0 {
+						cursor--
+					}
+					if scroll > cursor {
+						scroll = cursor
+					}
+				}
+			}
+			return nil
+		})
+	}
+}
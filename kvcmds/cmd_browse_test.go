@@ -0,0 +1,27 @@
+package kvcmds
+
+import "testing"
+
+func TestEditMode(t *testing.T) {
+	tests := []struct {
+		v    string
+		want int
+	}{
+		{"", editInline},
+		{"hello world", editInline},
+		{`{"name": "tikv", "tags": ["a", "b"]}`, editInline},
+		{"héllo, 世界", editInline},
+		{"{\n  \"name\": \"tikv\"\n}", editInEditor},
+		{"line 1\nline 2\n", editInEditor},
+		{"a\tb", editInEditor},
+		{"\x00\x01\x02", editRefused},
+		{"abc\xff", editRefused},
+		{"abc\r\n", editRefused},
+		{"\x1b[31mred", editRefused},
+	}
+	for _, tt := range tests {
+		if got := editMode([]byte(tt.v)); got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.v, got, tt.want)
+		}
+	}
+}
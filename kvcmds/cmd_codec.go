@@ -0,0 +1,406 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+	"github.com/tikv/client-go/v2/util/codec"
+)
+
+type CodecCmd struct{}
+
+var _ tcli.Cmd = CodecCmd{}
+
+func (c CodecCmd) Name() string    { return "codec" }
+func (c CodecCmd) Alias() []string { return []string{"codec"} }
+func (c CodecCmd) Help() string {
+	return `encode and decode TiDB memcomparable keys, use "codec --help" for more details`
+}
+
+func (c CodecCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	codec encode <type> <value> [<type> <value>...] <options>
+	codec decode <key> [<type>...]
+Types:
+	int, uint, float, bytes,
+	decimal                          memcomparable values, as in TiDB keys
+	datum:int, datum:uint,
+	datum:float, datum:bytes,
+	datum:decimal                    values prefixed by their datum flag, as in index keys
+	table, record, index             the t{id}, _r{handle} and _i{id} parts of TiDB keys
+	raw                              the literal itself, not encoded
+Options:
+	--var=<name>, also store the encoded key into a variable
+Description:
+	decode without types recognizes TiDB record and index keys, other keys are
+	decoded as a sequence of datums. Decimals take the precision and frac of
+	their literal, or of a (precision,frac) suffix like a DECIMAL(10,2) column
+	of an index. Duration and json datums are not supported.
+Examples:
+	codec encode table 45 record 1 --var=row
+	get $row
+	codec encode table 45 index 2 datum:bytes "alice" datum:int 7
+	codec encode table 45 index 3 datum:decimal "-12.50(10,2)"
+	codec decode h'74800000000000002d5f728000000000000001'
+	codec decode h'800000000000002a616263' int raw
+`
+	return s
+}
+
+// datum flags of the TiDB codec
+const (
+	datumNilFlag          byte = 0
+	datumBytesFlag        byte = 1
+	datumCompactBytesFlag byte = 2
+	datumIntFlag          byte = 3
+	datumUintFlag         byte = 4
+	datumFloatFlag        byte = 5
+	datumDecimalFlag      byte = 6
+	datumDurationFlag     byte = 7
+	datumVarintFlag       byte = 8
+	datumUvarintFlag      byte = 9
+	datumJSONFlag         byte = 10
+	datumMaxFlag          byte = 250
+)
+
+var (
+	tablePrefix  = []byte("t")
+	recordPrefix = []byte("_r")
+	indexPrefix  = []byte("_i")
+)
+
+func encodeFloat(b []byte, f float64) []byte {
+	u := math.Float64bits(f)
+	if f >= 0 {
+		u |= 1 << 63
+	} else {
+		u = ^u
+	}
+	return codec.EncodeUint(b, u)
+}
+
+func decodeFloat(b []byte) ([]byte, float64, error) {
+	b, u, err := codec.DecodeUint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if u&(1<<63) > 0 {
+		u &^= 1 << 63
+	} else {
+		u = ^u
+	}
+	return b, math.Float64frombits(u), nil
+}
+
+// encodeCodecValue appends the value encoded as typ
+func encodeCodecValue(b []byte, typ string, raw string) ([]byte, error) {
+	if typ == "raw" {
+		v, err := utils.GetStringLit(raw)
+		if err != nil {
+			return nil, err
+		}
+		return append(b, v...), nil
+	}
+	datum := strings.HasPrefix(typ, "datum:")
+	if datum {
+		typ = strings.TrimPrefix(typ, "datum:")
+	}
+	switch typ {
+	case "int", "table", "record", "index":
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, utils.NewParseError("invalid %s: %s", typ, raw)
+		}
+		switch {
+		case typ == "table":
+			b = append(b, tablePrefix...)
+		case typ == "record":
+			b = append(b, recordPrefix...)
+		case typ == "index":
+			b = append(b, indexPrefix...)
+		case datum:
+			b = append(b, datumIntFlag)
+		}
+		return codec.EncodeInt(b, v), nil
+	case "uint":
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, utils.NewParseError("invalid uint: %s", raw)
+		}
+		if datum {
+			b = append(b, datumUintFlag)
+		}
+		return codec.EncodeUint(b, v), nil
+	case "float":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, utils.NewParseError("invalid float: %s", raw)
+		}
+		if datum {
+			b = append(b, datumFloatFlag)
+		}
+		return encodeFloat(b, v), nil
+	case "bytes":
+		v, err := utils.GetStringLit(raw)
+		if err != nil {
+			return nil, err
+		}
+		if datum {
+			b = append(b, datumBytesFlag)
+		}
+		return codec.EncodeBytes(b, v), nil
+	case "decimal":
+		// the (precision,frac) suffix needs quotes in the shell
+		v, err := utils.GetStringLit(raw)
+		if err != nil {
+			return nil, err
+		}
+		if datum {
+			b = append(b, datumDecimalFlag)
+		}
+		return encodeDecimal(b, string(v))
+	}
+	return nil, utils.NewParseError("unknown type: %s", typ)
+}
+
+type codecPart struct {
+	typ   string
+	value string
+}
+
+// decodeCodecValue decodes one value of typ from the head of b
+func decodeCodecValue(b []byte, typ string) ([]byte, codecPart, error) {
+	part := codecPart{typ: typ}
+	if strings.HasPrefix(typ, "datum:") {
+		b, part, err := decodeDatum(b)
+		if err == nil && part.typ != typ {
+			err = fmt.Errorf("expected %s, found %s", typ, part.typ)
+		}
+		return b, part, err
+	}
+	var prefix []byte
+	switch typ {
+	case "table":
+		prefix = tablePrefix
+	case "record":
+		prefix = recordPrefix
+	case "index":
+		prefix = indexPrefix
+	}
+	if !bytes.HasPrefix(b, prefix) {
+		return nil, part, fmt.Errorf("%s prefix %q not found", typ, prefix)
+	}
+	b = b[len(prefix):]
+
+	var err error
+	switch typ {
+	case "int", "table", "record", "index":
+		var v int64
+		b, v, err = codec.DecodeInt(b)
+		part.value = strconv.FormatInt(v, 10)
+	case "uint":
+		var v uint64
+		b, v, err = codec.DecodeUint(b)
+		part.value = strconv.FormatUint(v, 10)
+	case "float":
+		var v float64
+		b, v, err = decodeFloat(b)
+		part.value = strconv.FormatFloat(v, 'g', -1, 64)
+	case "bytes":
+		var v []byte
+		b, v, err = codec.DecodeBytes(b, nil)
+		part.value = utils.Bytes2ReadableStrLit(v)
+	case "decimal":
+		b, part.value, err = decodeDecimal(b)
+	case "raw":
+		part.value = utils.Bytes2ReadableStrLit(b)
+		b = nil
+	default:
+		return nil, part, utils.NewParseError("unknown type: %s", typ)
+	}
+	if err != nil {
+		return nil, part, fmt.Errorf("decode %s: %v", typ, err)
+	}
+	return b, part, nil
+}
+
+// decodeDatum decodes one flagged datum from the head of b
+func decodeDatum(b []byte) ([]byte, codecPart, error) {
+	if len(b) == 0 {
+		return nil, codecPart{}, fmt.Errorf("no datum left")
+	}
+	flag := b[0]
+	b = b[1:]
+	var typ string
+	switch flag {
+	case datumNilFlag:
+		return b, codecPart{typ: "datum:null", value: "NULL"}, nil
+	case datumMaxFlag:
+		return b, codecPart{typ: "datum:max", value: "MAX"}, nil
+	case datumIntFlag:
+		typ = "int"
+	case datumUintFlag:
+		typ = "uint"
+	case datumFloatFlag:
+		typ = "float"
+	case datumBytesFlag:
+		typ = "bytes"
+	case datumDecimalFlag:
+		typ = "decimal"
+	case datumCompactBytesFlag:
+		b, n, err := codec.DecodeVarint(b)
+		if err != nil || n < 0 || int64(len(b)) < n {
+			return nil, codecPart{}, fmt.Errorf("invalid compact bytes datum")
+		}
+		return b[n:], codecPart{typ: "datum:bytes", value: utils.Bytes2ReadableStrLit(b[:n])}, nil
+	case datumVarintFlag:
+		b, v, err := codec.DecodeVarint(b)
+		return b, codecPart{typ: "datum:int", value: strconv.FormatInt(v, 10)}, err
+	case datumUvarintFlag:
+		b, v, err := codec.DecodeUvarint(b)
+		return b, codecPart{typ: "datum:uint", value: strconv.FormatUint(v, 10)}, err
+	case datumDurationFlag, datumJSONFlag:
+		return nil, codecPart{}, fmt.Errorf("datum flag %d is not supported", flag)
+	default:
+		return nil, codecPart{}, fmt.Errorf("unknown datum flag %d", flag)
+	}
+	b, part, err := decodeCodecValue(b, typ)
+	part.typ = "datum:" + typ
+	return b, part, err
+}
+
+// decodeCodecKey recognizes TiDB record and index keys, other keys are
+// decoded as datums
+func decodeCodecKey(b []byte) ([]codecPart, error) {
+	var parts []codecPart
+	rest := b
+	if len(b) >= len(tablePrefix)+8 && bytes.HasPrefix(b, tablePrefix) {
+		var types []string
+		rest = b[len(tablePrefix)+8:]
+		switch {
+		case bytes.HasPrefix(rest, recordPrefix) && len(rest) == len(recordPrefix)+8:
+			types = []string{"table", "record"}
+		case bytes.HasPrefix(rest, indexPrefix) && len(rest) >= len(indexPrefix)+8:
+			types = []string{"table", "index"}
+		default:
+			types = []string{"table"}
+		}
+		var err error
+		if parts, rest, err = decodeCodecTypes(b, types); err != nil {
+			return nil, err
+		}
+		// a common handle is encoded as datums
+		if len(types) == 1 && bytes.HasPrefix(rest, recordPrefix) {
+			parts = append(parts, codecPart{typ: "record", value: "common handle"})
+			rest = rest[len(recordPrefix):]
+		}
+	}
+	for len(rest) > 0 {
+		var part codecPart
+		var err error
+		if rest, part, err = decodeDatum(rest); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func decodeCodecTypes(b []byte, types []string) ([]codecPart, []byte, error) {
+	var parts []codecPart
+	for _, typ := range types {
+		var part codecPart
+		var err error
+		b, part, err = decodeCodecValue(b, typ)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, b, nil
+}
+
+func (c CodecCmd) encode(args []string, opt *properties.Properties) error {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return utils.NewParseError("usage: codec encode <type> <value> [<type> <value>...]")
+	}
+	var key []byte
+	for i := 0; i < len(args); i += 2 {
+		var err error
+		if key, err = encodeCodecValue(key, args[i], args[i+1]); err != nil {
+			return err
+		}
+	}
+	if name := opt.GetString(tcli.CodecOptVar, ""); name != "" {
+		utils.VarSet(name, key)
+	}
+	utils.PrintTable([][]string{{"Key"}, {utils.Bytes2ReadableStrLit(key)}})
+	return nil
+}
+
+func (c CodecCmd) decode(args []string) error {
+	if len(args) == 0 {
+		return utils.NewParseError("usage: codec decode <key> [<type>...]")
+	}
+	key, err := utils.GetStringLit(args[0])
+	if err != nil {
+		return err
+	}
+	var parts []codecPart
+	if len(args) == 1 {
+		if parts, err = decodeCodecKey(key); err != nil {
+			return fmt.Errorf("%v, pass the types to decode", err)
+		}
+	} else {
+		var rest []byte
+		if parts, rest, err = decodeCodecTypes(key, args[1:]); err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			parts = append(parts, codecPart{typ: "raw", value: utils.Bytes2ReadableStrLit(rest)})
+		}
+	}
+	data := [][]string{{"Type", "Value"}}
+	for _, part := range parts {
+		data = append(data, []string{part.typ, part.value})
+	}
+	utils.PrintTable(data)
+	return nil
+}
+
+func (c CodecCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			args, flags := utils.GetArgsAndOptionFlag(ic.RawArgs[1:])
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(flags, opt); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			switch args[0] {
+			case "encode":
+				return c.encode(args[1:], opt)
+			case "decode":
+				return c.decode(args[1:])
+			}
+			return utils.NewParseError("unknown codec action %s, should be encode or decode", args[0])
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package kvcmds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// TiDB encodes a decimal as its precision and frac bytes followed by the
+// MySQL binary format: the integer and fraction digits are packed by words
+// of 9 digits into 4 bytes each, the leading and trailing partial words
+// into dig2bytes of their digit count. Words are big endian, all bytes are
+// inverted for negative values and the sign bit is flipped, which keeps
+// decimals of the same precision and frac memcomparable.
+const (
+	decimalDigitsPerWord = 9
+	decimalWordSize      = 4
+	decimalMaxPrecision  = 65
+	decimalMaxFrac       = 30
+)
+
+var decimalDig2Bytes = [decimalDigitsPerWord + 1]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+
+// decimalBinSize returns the size of the binary format, without the
+// precision and frac bytes
+func decimalBinSize(precision, frac int) int {
+	digitsInt := precision - frac
+	return digitsInt/decimalDigitsPerWord*decimalWordSize + decimalDig2Bytes[digitsInt%decimalDigitsPerWord] +
+		frac/decimalDigitsPerWord*decimalWordSize + decimalDig2Bytes[frac%decimalDigitsPerWord]
+}
+
+// parseDecimal parses "-12.50" or "-12.50(10,2)". Without precision and
+// frac they are those of the literal, like TiDB's PrecisionAndFrac.
+func parseDecimal(s string) (neg bool, intDigits, fracDigits string, precision, frac int, err error) {
+	invalid := utils.NewParseError("invalid decimal: %s, should be like -12.50 or -12.50(10,2)", s)
+	num := s
+	explicit := false
+	if i := strings.IndexByte(s, '('); i >= 0 && strings.HasSuffix(s, ")") {
+		pf := strings.Split(s[i+1:len(s)-1], ",")
+		if len(pf) != 2 {
+			return false, "", "", 0, 0, invalid
+		}
+		if precision, err = strconv.Atoi(strings.TrimSpace(pf[0])); err != nil {
+			return false, "", "", 0, 0, invalid
+		}
+		if frac, err = strconv.Atoi(strings.TrimSpace(pf[1])); err != nil {
+			return false, "", "", 0, 0, invalid
+		}
+		num, explicit = s[:i], true
+	}
+	if strings.HasPrefix(num, "-") {
+		neg, num = true, num[1:]
+	} else {
+		num = strings.TrimPrefix(num, "+")
+	}
+	intDigits, fracDigits = num, ""
+	if i := strings.IndexByte(num, '.'); i >= 0 {
+		intDigits, fracDigits = num[:i], num[i+1:]
+	}
+	if intDigits == "" && fracDigits == "" {
+		return false, "", "", 0, 0, invalid
+	}
+	for _, c := range intDigits + fracDigits {
+		if c < '0' || c > '9' {
+			return false, "", "", 0, 0, invalid
+		}
+	}
+	intDigits = strings.TrimLeft(intDigits, "0")
+	if !explicit {
+		frac = len(fracDigits)
+		precision = len(intDigits) + frac
+		if precision == 0 {
+			precision = 1
+		}
+	}
+	switch {
+	case precision < 1 || precision > decimalMaxPrecision || frac < 0 || frac > decimalMaxFrac || frac > precision:
+		return false, "", "", 0, 0, utils.NewParseError("invalid decimal precision and frac (%d,%d)", precision, frac)
+	case len(intDigits) > precision-frac:
+		return false, "", "", 0, 0, utils.NewParseError("%s has more than %d integer digits", s, precision-frac)
+	case len(fracDigits) > frac:
+		return false, "", "", 0, 0, utils.NewParseError("%s has more than %d fraction digits", s, frac)
+	}
+	// -0 is encoded as 0
+	if strings.Trim(intDigits+fracDigits, "0") == "" {
+		neg = false
+	}
+	return neg, intDigits, fracDigits, precision, frac, nil
+}
+
+// putDecimalWord appends the digits as a big endian number of
+// dig2bytes[len(digits)] bytes
+func putDecimalWord(b []byte, digits string, mask byte) []byte {
+	v, _ := strconv.ParseUint("0"+digits, 10, 32)
+	size := decimalDig2Bytes[len(digits)]
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(uint(i)*8))^mask)
+	}
+	return b
+}
+
+// encodeDecimal appends a decimal literal as TiDB encodes it
+func encodeDecimal(b []byte, s string) ([]byte, error) {
+	neg, intDigits, fracDigits, precision, frac, err := parseDecimal(s)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, byte(precision), byte(frac))
+	digitsInt := precision - frac
+	// pad to the full width, so the words line up
+	intDigits = strings.Repeat("0", digitsInt-len(intDigits)) + intDigits
+	fracDigits += strings.Repeat("0", frac-len(fracDigits))
+
+	var mask byte
+	if neg {
+		mask = 0xff
+	}
+	start := len(b)
+	leading := digitsInt % decimalDigitsPerWord
+	b = putDecimalWord(b, intDigits[:leading], mask)
+	for i := leading; i < digitsInt; i += decimalDigitsPerWord {
+		b = putDecimalWord(b, intDigits[i:i+decimalDigitsPerWord], mask)
+	}
+	for i := 0; i < frac; i += decimalDigitsPerWord {
+		end := i + decimalDigitsPerWord
+		if end > frac {
+			end = frac
+		}
+		b = putDecimalWord(b, fracDigits[i:end], mask)
+	}
+	b[start] ^= 0x80
+	return b, nil
+}
+
+// decodeDecimal decodes an encoded decimal from the head of b, the value
+// only carries its precision and frac if they differ from the literal's
+func decodeDecimal(b []byte) ([]byte, string, error) {
+	if len(b) < 2 {
+		return nil, "", fmt.Errorf("invalid decimal")
+	}
+	precision, frac := int(b[0]), int(b[1])
+	if precision < 1 || precision > decimalMaxPrecision || frac > decimalMaxFrac || frac > precision {
+		return nil, "", fmt.Errorf("invalid decimal precision and frac (%d,%d)", precision, frac)
+	}
+	b = b[2:]
+	size := decimalBinSize(precision, frac)
+	if len(b) < size {
+		return nil, "", fmt.Errorf("decimal (%d,%d) needs %d bytes, %d left", precision, frac, size, len(b))
+	}
+	bin := append([]byte{}, b[:size]...)
+	b = b[size:]
+	var mask byte
+	neg := bin[0]&0x80 == 0
+	if neg {
+		mask = 0xff
+	}
+	bin[0] ^= 0x80
+
+	// readWord reads the word of n digits at the head of bin
+	readWord := func(n int) (string, error) {
+		size := decimalDig2Bytes[n]
+		var v uint64
+		for _, c := range bin[:size] {
+			v = v<<8 | uint64(c^mask)
+		}
+		bin = bin[size:]
+		digits := strconv.FormatUint(v, 10)
+		if len(digits) > n {
+			return "", fmt.Errorf("invalid decimal word %d", v)
+		}
+		return strings.Repeat("0", n-len(digits)) + digits, nil
+	}
+	digitsInt := precision - frac
+	var sb strings.Builder
+	leading := digitsInt % decimalDigitsPerWord
+	for i := 0; i < digitsInt; {
+		w := decimalDigitsPerWord
+		if i == 0 && leading > 0 {
+			w = leading
+		}
+		digits, err := readWord(w)
+		if err != nil {
+			return nil, "", err
+		}
+		sb.WriteString(digits)
+		i += w
+	}
+	intDigits := strings.TrimLeft(sb.String(), "0")
+	sb.Reset()
+	for i := 0; i < frac; i += decimalDigitsPerWord {
+		w := frac - i
+		if w > decimalDigitsPerWord {
+			w = decimalDigitsPerWord
+		}
+		digits, err := readWord(w)
+		if err != nil {
+			return nil, "", err
+		}
+		sb.WriteString(digits)
+	}
+	fracDigits := sb.String()
+
+	value := intDigits
+	if value == "" {
+		value = "0"
+	}
+	if frac > 0 {
+		value += "." + fracDigits
+	}
+	if neg {
+		value = "-" + value
+	}
+	// carry precision and frac when the literal alone doesn't give them
+	if _, _, _, p, f, err := parseDecimal(value); err != nil || p != precision || f != frac {
+		value = fmt.Sprintf("%s(%d,%d)", value, precision, frac)
+	}
+	return b, value, nil
+}
@@ -0,0 +1,161 @@
+package kvcmds
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestDecimalCodec(t *testing.T) {
+	tests := []struct {
+		in  string
+		hex string // precision, frac and the binary format
+		out string // decoded, empty if the same as in
+	}{
+		// the example of MySQL's decimal2bin
+		{"1234567890.1234", "0e04810dfb38d204d2", ""},
+		{"-1234567890.1234", "0e047ef204c72dfb2d", ""},
+		{"0", "0100" + "80", ""},
+		{"-0", "0100" + "80", "0"},
+		{"0.5", "0101" + "85", ""},
+		{"12.50(10,2)", "0a02" + "8000000c32", ""},
+		{"-12.50(10,2)", "0a02" + "7ffffff3cd", ""},
+		{"12.5(10,2)", "0a02" + "8000000c32", "12.50(10,2)"},
+		{"007.10", "0302" + "870a", "7.10"},
+		{"123456789012345678.000000001", "1b09" + "875bcd15" + "00bc614e" + "00000001", ""},
+	}
+	for _, tt := range tests {
+		b, err := encodeDecimal(nil, tt.in)
+		if err != nil {
+			t.Errorf("encode %s: %v", tt.in, err)
+			continue
+		}
+		if got := hex.EncodeToString(b); got != tt.hex {
+			t.Errorf("encode %s: got %s, want %s", tt.in, got, tt.hex)
+		}
+		rest, v, err := decodeDecimal(append(b, 'x'))
+		want := tt.out
+		if want == "" {
+			want = tt.in
+		}
+		if err != nil || v != want || !bytes.Equal(rest, []byte("x")) {
+			t.Errorf("decode %s: got %q, rest %q, %v, want %q", tt.hex, v, rest, err, want)
+		}
+	}
+}
+
+func TestDecimalCodecErrors(t *testing.T) {
+	for _, in := range []string{"", "-", "1.2.3", "abc", "123.45(4,2)", "1.234(10,2)", "1(70,0)", "1(2,3)", "1(2)"} {
+		if _, err := encodeDecimal(nil, in); err == nil {
+			t.Errorf("encode %q: expected an error", in)
+		}
+	}
+	for _, h := range []string{"", "0e04", "0e04810dfb", "0100" + "8a", "4600" + "80"} {
+		b, _ := hex.DecodeString(h)
+		if _, _, err := decodeDecimal(b); err == nil {
+			t.Errorf("decode %s: expected an error", h)
+		}
+	}
+}
+
+// decimals keep their order when encoded with the same precision and frac
+func TestDecimalCodecOrder(t *testing.T) {
+	values := []string{"-999.99", "-12.50", "-0.01", "0", "0.01", "12.49", "12.50", "999.99"}
+	var last []byte
+	for _, v := range values {
+		b, err := encodeDecimal(nil, v+"(5,2)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last != nil && bytes.Compare(last, b) >= 0 {
+			t.Errorf("%s is not encoded after the previous value", v)
+		}
+		last = b
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		args []string // type value pairs
+		hex  string
+	}{
+		{[]string{"table", "45", "record", "1"}, "74800000000000002d5f728000000000000001"},
+		{[]string{"int", "-1"}, "7fffffffffffffff"},
+		{[]string{"uint", "42"}, "000000000000002a"},
+		{[]string{"float", "1.5"}, "bff8000000000000"},
+		{[]string{"float", "-1.5"}, "4007ffffffffffff"},
+		{[]string{"bytes", "abc"}, "6162630000000000fa"},
+		{[]string{"datum:int", "7", "datum:bytes", "a"}, "038000000000000007" + "016100000000000000f8"},
+		{[]string{"datum:uint", "1", "datum:float", "0"}, "040000000000000001" + "058000000000000000"},
+		{[]string{"table", "45", "index", "2", "datum:decimal", "1.5"}, "74800000000000002d5f698000000000000002" + "060201" + "8105"},
+	}
+	for _, tt := range tests {
+		var key []byte
+		var types []string
+		for i := 0; i < len(tt.args); i += 2 {
+			var err error
+			if key, err = encodeCodecValue(key, tt.args[i], tt.args[i+1]); err != nil {
+				t.Fatalf("encode %v: %v", tt.args, err)
+			}
+			types = append(types, tt.args[i])
+		}
+		if got := hex.EncodeToString(key); got != tt.hex {
+			t.Errorf("encode %v: got %s, want %s", tt.args, got, tt.hex)
+		}
+		parts, rest, err := decodeCodecTypes(key, types)
+		if err != nil || len(rest) > 0 {
+			t.Errorf("decode %s as %v: %v, rest %x", tt.hex, types, err, rest)
+			continue
+		}
+		for i, part := range parts {
+			want := tt.args[2*i+1]
+			if types[i] == "bytes" || types[i] == "datum:bytes" {
+				want = `"` + want + `"`
+			}
+			if part.typ != types[i] || part.value != want {
+				t.Errorf("decode %s: part %d is %s %s, want %s %s", tt.hex, i, part.typ, part.value, types[i], want)
+			}
+		}
+	}
+}
+
+func TestDecodeCodecKey(t *testing.T) {
+	tests := []struct {
+		hex     string
+		want    []string // type=value
+		wantErr bool
+	}{
+		{hex: "74800000000000002d5f728000000000000001", want: []string{"table=45", "record=1"}},
+		{hex: "74800000000000002d5f698000000000000002" + "038000000000000007" + "00",
+			want: []string{"table=45", "index=2", "datum:int=7", "datum:null=NULL"}},
+		// a common handle is a sequence of datums
+		{hex: "74800000000000002d5f72" + "016100000000000000f8", want: []string{"table=45", "record=common handle", `datum:bytes="a"`}},
+		{hex: "74800000000000002d", want: []string{"table=45"}},
+		{hex: "0206616263", want: []string{`datum:bytes="abc"`}},
+		{hex: "0801" + "09ac02" + "fa", want: []string{"datum:int=-1", "datum:uint=300", "datum:max=MAX"}},
+		{hex: "06" + "0e04810dfb38d204d2", want: []string{"datum:decimal=1234567890.1234"}},
+		{hex: "0780", wantErr: true},
+		{hex: "0a00", wantErr: true},
+		{hex: "63", wantErr: true},
+		{hex: "03800000", wantErr: true},
+		{hex: "0210616263", wantErr: true},
+	}
+	for _, tt := range tests {
+		b, _ := hex.DecodeString(tt.hex)
+		parts, err := decodeCodecKey(b)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("decode %s: expected an error, got %v", tt.hex, parts)
+			}
+			continue
+		}
+		var got []string
+		for _, part := range parts {
+			got = append(got, part.typ+"="+part.value)
+		}
+		if err != nil || strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("decode %s: got %v, %v, want %v", tt.hex, got, err, tt.want)
+		}
+	}
+}
@@ -65,7 +65,11 @@ func (c CountCmd) Handler() func(ctx context.Context) {
 					prefix = []byte("\x00")
 					scanOpt.Set(tcli.ScanOptStrictPrefix, "false")
 				}
-				_, cnt, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), prefix)
+				kvClient, err := client.GetScanClient()
+				if err != nil {
+					return err
+				}
+				_, cnt, err := kvClient.Scan(utils.ContextWithProp(context.TODO(), scanOpt), prefix)
 				if err != nil {
 					return err
 				}
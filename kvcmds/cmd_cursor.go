@@ -0,0 +1,170 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// the session cursor is kept in a variable, so "get $cursor" reads the key
+// under it and .session shows it
+const cursorVar = "cursor"
+
+var errNoCursor = errors.New(`no cursor, use "seek <key>" first`)
+
+// cursorScan scans from key with the options of a cursor command, moves the
+// cursor to the last key returned and prints the keys
+func cursorScan(key []byte, args []string, n int, reverse bool) error {
+	scanOpt, err := scanOptsFromArgs(args)
+	if err != nil {
+		return err
+	}
+	scanOpt.Set(tcli.ScanOptLimit, strconv.Itoa(n))
+	scanOpt.Set(tcli.ScanOptReverse, strconv.FormatBool(reverse))
+	scanOpt.Set(tcli.ScanOptStrictPrefix, "false")
+	scanOpt.Set(tcli.ScanOptCountOnly, "false")
+	scanOpt.Set(tcli.ScanOptEnd, "")
+	kvs, _, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), key)
+	if err != nil {
+		return err
+	}
+	if len(kvs) == 0 {
+		fmt.Fprintln(os.Stderr, "No more keys, the cursor is not moved")
+		return nil
+	}
+	utils.VarSet(cursorVar, kvs[len(kvs)-1].K)
+	kvs.Print()
+	return nil
+}
+
+// cursorCount parses the optional [n] of next and prev, returns the index
+// of the first option
+func cursorCount(args []string) (int, int, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		return 1, 0, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, 0, utils.NewParseError("invalid count: %s", args[0])
+	}
+	return n, 1, nil
+}
+
+type SeekCmd struct{}
+
+var _ tcli.Cmd = SeekCmd{}
+
+func (c SeekCmd) Name() string    { return "seek" }
+func (c SeekCmd) Alias() []string { return []string{"seek"} }
+func (c SeekCmd) Help() string {
+	return `move the session cursor to the first key >= key, usage: seek <key> <options>`
+}
+
+func (c SeekCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	seek <key> <options>
+	next [n] <options>
+	prev [n] <options>
+Options:
+	--key-only, don't show values
+Description:
+	seek places the cursor, next and prev show the n keys after or before
+	it (default 1) and move it to the last key shown. The cursor key is
+	kept in the $cursor variable.
+Examples:
+	seek "user_100"
+	next 10 --key-only
+	prev
+	get $cursor
+`
+	return s
+}
+
+func (c SeekCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			key, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			return cursorScan(key, ic.RawArgs[2:], 1, false)
+		})
+	}
+}
+
+type NextCmd struct{}
+
+var _ tcli.Cmd = NextCmd{}
+
+func (c NextCmd) Name() string    { return "next" }
+func (c NextCmd) Alias() []string { return []string{"next"} }
+func (c NextCmd) Help() string {
+	return `show the keys after the session cursor and move it, usage: next [n] <options>`
+}
+
+func (c NextCmd) LongHelp() string {
+	return SeekCmd{}.LongHelp()
+}
+
+func (c NextCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			cur, ok := utils.VarGet(cursorVar)
+			if !ok {
+				return errNoCursor
+			}
+			n, i, err := cursorCount(ic.RawArgs[1:])
+			if err != nil {
+				return err
+			}
+			return cursorScan(utils.NextKey(cur), ic.RawArgs[1+i:], n, false)
+		})
+	}
+}
+
+type PrevCmd struct{}
+
+var _ tcli.Cmd = PrevCmd{}
+
+func (c PrevCmd) Name() string    { return "prev" }
+func (c PrevCmd) Alias() []string { return []string{"prev"} }
+func (c PrevCmd) Help() string {
+	return `show the keys before the session cursor and move it, usage: prev [n] <options>`
+}
+
+func (c PrevCmd) LongHelp() string {
+	return SeekCmd{}.LongHelp()
+}
+
+func (c PrevCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			cur, ok := utils.VarGet(cursorVar)
+			if !ok {
+				return errNoCursor
+			}
+			n, i, err := cursorCount(ic.RawArgs[1:])
+			if err != nil {
+				return err
+			}
+			// a reverse scan starts before its start key
+			return cursorScan(cur, ic.RawArgs[1+i:], n, true)
+		})
+	}
+}
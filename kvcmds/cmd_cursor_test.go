@@ -0,0 +1,77 @@
+package kvcmds
+
+import (
+	"os"
+	"testing"
+
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// muteStdout drops what the commands print during a test
+func muteStdout(t *testing.T) {
+	t.Helper()
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devnull
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		devnull.Close()
+	})
+}
+
+func TestCursorCount(t *testing.T) {
+	tests := []struct {
+		args    []string
+		n, i    int
+		wantErr bool
+	}{
+		{nil, 1, 0, false},
+		{[]string{"--key-only"}, 1, 0, false},
+		{[]string{"10"}, 10, 1, false},
+		{[]string{"10", "--key-only"}, 10, 1, false},
+		{[]string{"0"}, 0, 0, true},
+		{[]string{"-1"}, 0, 0, true},
+		{[]string{"ten"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		n, i, err := cursorCount(tt.args)
+		if (err != nil) != tt.wantErr || n != tt.n || i != tt.i {
+			t.Errorf("cursorCount(%v) = %d, %d, %v, want %d, %d", tt.args, n, i, err, tt.n, tt.i)
+		}
+	}
+}
+
+func TestCursorMoves(t *testing.T) {
+	putTestKeys(t, "cur_", 20)
+	utils.InitBuiltinVaribles()
+	muteStdout(t)
+
+	cursor := func() string {
+		k, _ := utils.VarGet(cursorVar)
+		return string(k)
+	}
+	steps := []struct {
+		name string
+		run  func() error
+		want string
+	}{
+		{"seek", func() error { return cursorScan([]byte("cur_00005"), nil, 1, false) }, "cur_00005"},
+		{"seek between keys", func() error { return cursorScan([]byte("cur_00004x"), nil, 1, false) }, "cur_00005"},
+		{"next 3", func() error { return cursorScan(utils.NextKey([]byte(cursor())), nil, 3, false) }, "cur_00008"},
+		{"prev 2", func() error { return cursorScan([]byte(cursor()), nil, 2, true) }, "cur_00006"},
+		{"prev past the prefix", func() error { return cursorScan([]byte("cur_00000"), nil, 1, true) }, "cur"},
+		{"next to the last key", func() error { return cursorScan([]byte("cur_00019"), []string{"--key-only"}, 5, false) }, "cur\xff"},
+		{"next past the end", func() error { return cursorScan(utils.NextKey([]byte(cursor())), nil, 1, false) }, "cur\xff"},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := cursor(); got != step.want {
+			t.Errorf("%s: cursor at %q, want %q", step.name, got, step.want)
+		}
+	}
+}
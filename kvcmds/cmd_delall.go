@@ -9,6 +9,7 @@ import (
 	"github.com/c4pt0r/tcli"
 	"github.com/c4pt0r/tcli/client"
 	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
 )
 
 type DeleteAllCmd struct{}
@@ -27,7 +28,9 @@ func (c DeleteAllCmd) LongHelp() string {
 Usage:
 	delall
 Options:
-	--yes, force yes
+	--yes, --force, force yes
+	--dry-run, only report how many keys would be deleted
+	--sample=<n>, with --dry-run, list the first n keys that would be deleted, default: 0
 Alias:
 	dela, removeall, rma
 `
@@ -37,11 +40,18 @@ Alias:
 func (c DeleteAllCmd) Handler() func(ctx context.Context) {
 	return func(ctx context.Context) {
 		utils.OutputWithElapse(func() error {
-			var yes bool
-			if utils.HasForceYes(ctx) {
-				yes = true
-			} else {
-				yes = utils.AskYesNo(fmt.Sprintf("Delete all keys, are you sure?"), "no") == 1
+			ic := utils.ExtractIshellContext(ctx)
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(ic.Args, opt); err != nil {
+				return err
+			}
+			if opt.GetBool(tcli.DeleteOptDryRun, false) {
+				return previewDelete([]byte(""), -1, opt.GetInt(tcli.DeleteOptSample, 0))
+			}
+
+			yes, err := confirmDelete(ctx, []byte(""), -1, "Delete all keys, are you sure?")
+			if err != nil {
+				return err
 			}
 			if yes {
 				utils.Print("Your call")
@@ -28,8 +28,14 @@ Usage:
 Alias:
 	deletep, removep, rmp
 Options:
-	--yes, force yes
+	--yes, --force, force yes
 	--limit=<limit>, default: 1000
+	--dry-run, only report how many keys would be deleted
+	--sample=<n>, with --dry-run, list the first n keys that would be deleted, default: 0
+	--bg, delete in the background, in batches of 1000 keys, see "jobs"
+Notes:
+	set sys.confirm_threshold to skip confirmation for deletes affecting at most that many keys:
+	sysvar sys.confirm_threshold="100"
 `
 	return s
 }
@@ -56,14 +62,22 @@ func (c DeletePrefixCmd) Handler() func(ctx context.Context) {
 			opt.Set(tcli.DeleteOptWithPrefix, "true")
 			limit := opt.GetInt(tcli.DeleteOptLimit, 1000)
 
-			var yes bool
-			if utils.HasForceYes(ctx) {
-				yes = true
-			} else {
-				yes = utils.AskYesNo("Are you sure to delete kv pairs with prefix: %s", string(k)) == 1
+			if opt.GetBool(tcli.DeleteOptDryRun, false) {
+				return previewDelete(k, limit, opt.GetInt(tcli.DeleteOptSample, 0))
 			}
 
-			if yes {
+			yes, err := confirmDelete(ctx, k, limit, fmt.Sprintf("Are you sure to delete kv pairs with prefix: %s", k))
+			if err != nil {
+				return err
+			}
+			if yes && opt.GetBool(tcli.DeleteOptBackground, false) {
+				run := func(ctx context.Context, job *utils.Job) error {
+					lastKey, cnt, err := deletePrefixBatches(ctx, job, k, limit)
+					job.Print(fmt.Sprintf("Affected Keys: %d, Last Key: %s", cnt, utils.Bytes2ReadableStrLit(lastKey)))
+					return err
+				}
+				return runBulk(ic, true, run)
+			} else if yes {
 				utils.Print("Your call")
 				lastKey, cnt, err := client.GetTiKVClient().DeletePrefix(ctx, k, limit)
 				if err != nil {
@@ -84,3 +98,111 @@ func (c DeletePrefixCmd) Handler() func(ctx context.Context) {
 		})
 	}
 }
+
+// deletePrefixBatches deletes up to limit keys with prefix in batches, so
+// that a background job can be throttled and cancelled between them
+func deletePrefixBatches(ctx context.Context, job *utils.Job, prefix []byte, limit int) (client.Key, int, error) {
+	var lastKey client.Key
+	total := 0
+	for total < limit {
+		n := limit - total
+		if n > 1000 {
+			n = 1000
+		}
+		key, cnt, err := client.GetTiKVClient().DeletePrefix(context.TODO(), prefix, n)
+		total += cnt
+		if err != nil {
+			return lastKey, total, err
+		}
+		if cnt == 0 {
+			break
+		}
+		lastKey = key
+		job.Print(fmt.Sprintf("Deleted %d keys, last key: %s", total, utils.Bytes2ReadableStrLit(lastKey)))
+		if err := job.Done(ctx, cnt); err != nil {
+			return lastKey, total, err
+		}
+		if cnt < n {
+			break
+		}
+	}
+	return lastKey, total, nil
+}
+
+// countDeleteKeys returns how many keys with prefix a delete would affect,
+// counting stops at max, max < 0 means no limit. Keys are counted on the
+// primary cluster, where the delete runs, with "*" taken literally like the
+// delete does.
+func countDeleteKeys(prefix []byte, max int) (int, error) {
+	if max < 0 {
+		scanOpt := properties.NewProperties()
+		scanOpt.Set(tcli.ScanOptKeyOnly, "true")
+		scanOpt.Set(tcli.ScanOptCountOnly, "true")
+		scanOpt.Set(tcli.ScanOptStrictPrefix, "true")
+		_, cnt, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), prefix)
+		return cnt, err
+	}
+	if max == 0 {
+		return 0, nil
+	}
+	total := 0
+	err := client.ScanBatches(context.TODO(), client.GetTiKVClient(), prefix, prefix, true, 1000, max, func(kvs client.KVS) error {
+		total += len(kvs)
+		return nil
+	})
+	return total, err
+}
+
+// confirmDelete asks the user before deleting keys with prefix. When the
+// sys.confirm_threshold variable is set, deletes affecting no more keys
+// than the threshold go through without asking, bigger ones show the count.
+func confirmDelete(ctx context.Context, prefix []byte, limit int, msg string) (bool, error) {
+	if utils.HasForceYes(ctx) {
+		return true, nil
+	}
+	threshold := utils.SysVarGetInt(utils.SysVarConfirmThresholdKey, 0)
+	if threshold > 0 {
+		// one key past the threshold is enough to ask
+		max := threshold + 1
+		if limit >= 0 && limit < max {
+			max = limit
+		}
+		cnt, err := countDeleteKeys(prefix, max)
+		if err != nil {
+			return false, err
+		}
+		if cnt <= threshold {
+			return true, nil
+		}
+		msg = fmt.Sprintf("%s (more than sys.confirm_threshold: %d keys)", msg, threshold)
+	}
+	return utils.AskYesNo(msg, "no") == 1, nil
+}
+
+// previewDelete prints how many keys with prefix a delete would affect,
+// and the first sample keys, without writing anything. limit < 0 means no limit.
+func previewDelete(prefix []byte, limit int, sample int) error {
+	cnt, err := countDeleteKeys(prefix, limit)
+	if err != nil {
+		return err
+	}
+	if sample > cnt {
+		sample = cnt
+	}
+	if sample > 0 {
+		scanOpt := properties.NewProperties()
+		scanOpt.Set(tcli.ScanOptKeyOnly, "true")
+		scanOpt.Set(tcli.ScanOptStrictPrefix, "true")
+		scanOpt.Set(tcli.ScanOptLimit, fmt.Sprintf("%d", sample))
+		kvs, _, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), prefix)
+		if err != nil {
+			return err
+		}
+		kvs.Print()
+	}
+	result := []client.KV{
+		{K: []byte("Affected Keys (dry run)"), V: []byte(fmt.Sprintf("%d", cnt))},
+	}
+	client.KVS(result).Print()
+	return nil
+}
@@ -0,0 +1,52 @@
+package kvcmds
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/c4pt0r/tcli/client"
+)
+
+// putTestKeys fills the global client with n keys of prefix, and keys just
+// around it
+func putTestKeys(t *testing.T, prefix string, n int) {
+	t.Helper()
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	kvs := []client.KV{
+		{K: client.Key(prefix[:len(prefix)-1]), V: client.Value("before")},
+		{K: client.Key(prefix[:len(prefix)-1] + "\xff"), V: client.Value("after")},
+	}
+	for i := 0; i < n; i++ {
+		kvs = append(kvs, client.KV{K: client.Key(fmt.Sprintf("%s%05d", prefix, i)), V: client.Value("v")})
+	}
+	if err := client.GetTiKVClient().BatchPut(context.TODO(), kvs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCountDeleteKeys(t *testing.T) {
+	putTestKeys(t, "del_", 2500)
+	tests := []struct {
+		prefix string
+		max    int
+		want   int
+	}{
+		{"del_", -1, 2500},
+		{"del_", 0, 0},
+		{"del_", 101, 101},
+		{"del_", 1000, 1000},
+		{"del_", 2000, 2000},
+		{"del_", 5000, 2500},
+		{"del_01", 5000, 1000},
+		{"nodel_", 10, 0},
+	}
+	for _, tt := range tests {
+		got, err := countDeleteKeys([]byte(tt.prefix), tt.max)
+		if err != nil || got != tt.want {
+			t.Errorf("countDeleteKeys(%q, %d) = %d, %v, want %d", tt.prefix, tt.max, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+type DescribeCmd struct{}
+
+var _ tcli.Cmd = DescribeCmd{}
+
+func (c DescribeCmd) Name() string    { return "describe" }
+func (c DescribeCmd) Alias() []string { return []string{"describe", "desc"} }
+func (c DescribeCmd) Help() string {
+	return `show the structure of a value and commands to read its fields, use "describe --help" for more details`
+}
+
+func (c DescribeCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	describe <key>
+Description:
+	For a JSON value, every field is listed with its pp path, its type and
+	an example, elements of arrays are merged under path[]. Then commands
+	reading the fields are suggested: pp for the key, and scanp --flatten
+	for the keys sharing its prefix (up to the last '_', ':', '/', '.' or
+	'-' of the key).
+Examples:
+	describe "user_1"
+`
+	return s
+}
+
+type describeField struct {
+	path  string
+	types []string
+	// an example value, the first one found
+	example string
+	leaf    bool
+}
+
+// the longest example shown
+const describeExampleWidth = 40
+
+func describePath(path, name string) string {
+	if name != "" && strings.IndexFunc(name, func(r rune) bool { return r > 0x7f || !isJqIdentChar(byte(r)) }) < 0 {
+		return path + "." + name
+	}
+	return describeElem(path, strconv.Quote(name))
+}
+
+// describeElem returns the path of path[sub], .[sub] at the top level
+func describeElem(path, sub string) string {
+	if path == "" {
+		path = "."
+	}
+	return path + "[" + sub + "]"
+}
+
+func describeExample(v interface{}) string {
+	switch o := v.(type) {
+	case []interface{}:
+		return fmt.Sprintf("[%d items]", len(o))
+	case map[string]interface{}:
+		return fmt.Sprintf("{%d fields}", len(o))
+	}
+	buf, _ := json.Marshal(v)
+	if r := []rune(string(buf)); len(r) > describeExampleWidth {
+		return string(r[:describeExampleWidth-3]) + "..."
+	}
+	return string(buf)
+}
+
+// describeJSON adds v at path and its children to fields, a path seen
+// before (an array element) only adds its type if it's a new one
+func describeJSON(path string, v interface{}, fields *[]*describeField, index map[string]*describeField) {
+	typ := jqTypeName(v)
+	f, ok := index[path]
+	if !ok {
+		f = &describeField{path: path, example: describeExample(v)}
+		index[path] = f
+		*fields = append(*fields, f)
+	}
+	found := false
+	for _, t := range f.types {
+		found = found || t == typ
+	}
+	if !found {
+		f.types = append(f.types, typ)
+	}
+	switch o := v.(type) {
+	case []interface{}:
+		for _, e := range o {
+			describeJSON(describeElem(path, ""), e, fields, index)
+		}
+	case map[string]interface{}:
+		for _, k := range sortedJqKeys(o) {
+			describeJSON(describePath(path, k), o[k], fields, index)
+		}
+	default:
+		f.leaf = true
+	}
+}
+
+// keyPrefixOf returns the key up to its last separator, nil if it has none
+func keyPrefixOf(k []byte) []byte {
+	if i := bytes.LastIndexAny(k, "_:/.-"); i > 0 {
+		return k[:i+1]
+	}
+	return nil
+}
+
+// shellQuote quotes an argument if the shell would split or unquote it
+func shellQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t'\"[]|\\") {
+		return arg
+	}
+	return "'" + arg + "'"
+}
+
+func (c DescribeCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			k, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			kv, err := client.GetTiKVClient().Get(context.TODO(), client.Key(k))
+			if err != nil {
+				return err
+			}
+			var v interface{}
+			dec := json.NewDecoder(bytes.NewReader(kv.V))
+			dec.UseNumber()
+			if !json.Valid(kv.V) || dec.Decode(&v) != nil {
+				typ := "bytes"
+				if utf8.Valid(kv.V) {
+					typ = "string"
+				}
+				utils.PrintTable([][]string{
+					{"Path", "Type", "Example"},
+					{".", fmt.Sprintf("%s (not JSON, %d bytes)", typ, len(kv.V)), describeExample(string(kv.V))},
+				})
+				return nil
+			}
+
+			var fields []*describeField
+			describeJSON("", v, &fields, make(map[string]*describeField))
+			output := [][]string{{"Path", "Type", "Example"}}
+			for _, f := range fields {
+				path := f.path
+				if path == "" {
+					path = "."
+				}
+				output = append(output, []string{path, strings.Join(f.types, "|"), f.example})
+			}
+			utils.PrintTable(output)
+
+			// suggest reading the first leaf field, and the top-level fields
+			// of the whole prefix
+			var queries []string
+			for _, f := range fields {
+				if f.leaf && f.path != "" {
+					queries = append(queries, fmt.Sprintf("pp %s %s", utils.Bytes2ReadableStrLit(k), shellQuote(f.path)))
+					break
+				}
+			}
+			if o, ok := v.(map[string]interface{}); ok {
+				if prefix := keyPrefixOf(k); prefix != nil {
+					columns := []string{"key"}
+					for _, name := range sortedJqKeys(o) {
+						columns = append(columns, "value."+name)
+					}
+					queries = append(queries, fmt.Sprintf("scanp %s --flatten --columns=%s",
+						utils.Bytes2ReadableStrLit(prefix), shellQuote(strings.Join(columns, ","))))
+				}
+			}
+			if len(queries) > 0 {
+				utils.Print("Queries:")
+				for _, q := range queries {
+					utils.Print("\t" + q)
+				}
+			}
+			return nil
+		})
+	}
+}
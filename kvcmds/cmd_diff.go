@@ -0,0 +1,329 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type DiffCmd struct{}
+
+var _ tcli.Cmd = DiffCmd{}
+
+func (c DiffCmd) Name() string    { return "diff" }
+func (c DiffCmd) Alias() []string { return []string{"diff"} }
+func (c DiffCmd) Help() string {
+	return `compare a range at two timestamps or two backup files, use "diff --help" for more details`
+}
+
+func (c DiffCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	diff ts <key prefix | *> <ts1> [ts2] <options>
+	diff ts <start key> <ts1> [ts2] --end=<end key>
+	diff files <old backup file> <new backup file>
+Options:
+	--end=<end key>, compare [start key, end key) instead of a prefix
+	--batch-size=<n>, keys read per request, default 1000
+Description:
+	ts reads the range at ts1 and at ts2, ts2 defaults to now. Timestamps
+	are TSOs, local times ("2006-01-02 15:04:05") or durations before now,
+	they must be after the GC safe point. Txn mode only.
+	files compares two files written by backup.
+	Keys only found on the new side are added, only on the old side are
+	removed, and keys with different values are changed.
+Examples:
+	diff ts "order:" 1h
+	diff ts "order:" "2026-10-17 08:00:00" "2026-10-18 08:00:00"
+	diff files orders_mon.csv orders_tue.csv
+`
+	return s
+}
+
+// the differences printed, the others are only counted
+const diffMaxShown = 100
+
+// kvSource streams kv pairs in key order, Next returns nil at the end
+type kvSource interface {
+	Next() (*client.KV, error)
+}
+
+// snapshotSource reads a range at a timestamp, batch by batch
+type snapshotSource struct {
+	rc        client.ReplicaReadClient
+	start     []byte
+	end       []byte
+	ts        uint64
+	batchSize int
+	buf       client.KVS
+	done      bool
+}
+
+func (s *snapshotSource) Next() (*client.KV, error) {
+	if len(s.buf) == 0 {
+		if s.done {
+			return nil, nil
+		}
+		kvs, err := s.rc.ScanReplica(context.TODO(), s.start, s.end, s.ts, false, s.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		s.done = len(kvs) < s.batchSize
+		if len(kvs) == 0 {
+			return nil, nil
+		}
+		s.start = utils.NextKey(kvs[len(kvs)-1].K)
+		s.buf = kvs
+	}
+	kv := &s.buf[0]
+	s.buf = s.buf[1:]
+	return kv, nil
+}
+
+// backupFileSource reads the rows of a file written by backup
+type backupFileSource struct {
+	name    string
+	r       *csv.Reader
+	started bool
+	last    []byte
+}
+
+func (s *backupFileSource) Next() (*client.KV, error) {
+	for {
+		rec, err := s.r.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the first row is the header
+		if !s.started {
+			s.started = true
+			if len(rec) == 2 && rec[0] == "Key" && rec[1] == "Value" {
+				continue
+			}
+		}
+		if len(rec) != 2 || rec[0] == "" || rec[1] == "" {
+			return nil, fmt.Errorf("invalid backup record in %s: %v", s.name, rec)
+		}
+		k, err := utils.GetStringLit(rec[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := utils.GetStringLit(rec[1])
+		if err != nil {
+			return nil, err
+		}
+		if s.last != nil && bytes.Compare(k, s.last) <= 0 {
+			return nil, fmt.Errorf("%s is not sorted by key at %s, only files written by backup can be compared",
+				s.name, utils.Bytes2ReadableStrLit(k))
+		}
+		s.last = k
+		return &client.KV{K: k, V: v}, nil
+	}
+}
+
+type diffStats struct {
+	added, removed, changed, unchanged int
+}
+
+// diffSources merges the sorted pairs of the from and to sources, onDiff is
+// called for every key added (o is nil), removed (n is nil) or changed
+func diffSources(from, to kvSource, onDiff func(k []byte, o, n *client.KV)) (diffStats, error) {
+	var stats diffStats
+	o, err := from.Next()
+	if err != nil {
+		return stats, err
+	}
+	n, err := to.Next()
+	if err != nil {
+		return stats, err
+	}
+	for o != nil || n != nil {
+		cmp := 0
+		switch {
+		case o == nil:
+			cmp = 1
+		case n == nil:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(o.K, n.K)
+		}
+		switch {
+		case cmp < 0:
+			stats.removed++
+			onDiff(o.K, o, nil)
+		case cmp > 0:
+			stats.added++
+			onDiff(n.K, nil, n)
+		case !bytes.Equal(o.V, n.V):
+			stats.changed++
+			onDiff(o.K, o, n)
+		default:
+			stats.unchanged++
+		}
+		if cmp <= 0 {
+			if o, err = from.Next(); err != nil {
+				return stats, err
+			}
+		}
+		if cmp >= 0 {
+			if n, err = to.Next(); err != nil {
+				return stats, err
+			}
+		}
+		if total := stats.added + stats.removed + stats.changed + stats.unchanged; total%10000 == 0 {
+			fmt.Fprintf(os.Stderr, "\r%d keys compared", total)
+		}
+	}
+	return stats, nil
+}
+
+func diffPreview(kv *client.KV) string {
+	if kv == nil {
+		return ""
+	}
+	preview := utils.Bytes2ReadableStrLit(previewValue(kv.V))
+	if len(kv.V) > len(previewValue(kv.V)) {
+		preview += "..."
+	}
+	return preview
+}
+
+// timestampSources returns the sources of diff ts
+func (c DiffCmd) timestampSources(args, rawArgs []string) (kvSource, kvSource, error) {
+	rc, ok := client.GetTiKVClient().(client.ReplicaReadClient)
+	if !ok {
+		return nil, nil, errors.New("diff ts is only supported in txn mode")
+	}
+	if len(args) < 3 {
+		return nil, nil, utils.NewParseError("usage: diff ts <key prefix | *> <ts1> [ts2]")
+	}
+	startKey, err := utils.GetStringLit(rawArgs[2])
+	if err != nil {
+		return nil, nil, err
+	}
+	// options come from the raw args, shell-splitting would strip the
+	// quotes of a h'...' end key
+	_, flags := utils.GetArgsAndOptionFlag(rawArgs[3:])
+	opt := properties.NewProperties()
+	if err := utils.SetOptByString(flags, opt); err != nil {
+		return nil, nil, err
+	}
+	batchSize := opt.GetInt(tcli.DiffOptBatchSize, 1000)
+	if batchSize <= 0 {
+		return nil, nil, utils.NewParseError("batch size should be positive")
+	}
+	var endKey []byte
+	if end := opt.GetString(tcli.DiffOptEnd, ""); end != "" {
+		if endKey, err = utils.GetStringLit(end); err != nil {
+			return nil, nil, err
+		}
+	} else if string(startKey) == "*" {
+		startKey = []byte{}
+	} else {
+		endKey = utils.PrefixNextKey(startKey)
+	}
+
+	now, err := rc.GetCurrentTS(context.TODO())
+	if err != nil {
+		return nil, nil, err
+	}
+	ts1, err := utils.ParseTS(args[2], now)
+	if err != nil {
+		return nil, nil, err
+	}
+	ts2 := now
+	if len(args) > 3 {
+		if ts2, err = utils.ParseTS(args[3], now); err != nil {
+			return nil, nil, err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Comparing %s with %s\n", utils.FormatTS(ts1), utils.FormatTS(ts2))
+	from := &snapshotSource{rc: rc, start: startKey, end: endKey, ts: ts1, batchSize: batchSize}
+	to := &snapshotSource{rc: rc, start: startKey, end: endKey, ts: ts2, batchSize: batchSize}
+	return from, to, nil
+}
+
+func (c DiffCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			// timestamps may be quoted times with a space, positional args
+			// come from the shell-split args
+			args, _ := utils.GetArgsAndOptionFlag(ic.Args)
+			if len(args) < 3 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			var from, to kvSource
+			switch args[0] {
+			case "ts":
+				var err error
+				if from, to, err = c.timestampSources(args, ic.RawArgs); err != nil {
+					return err
+				}
+			case "files":
+				if len(args) < 3 {
+					return utils.NewParseError("usage: diff files <old backup file> <new backup file>")
+				}
+				oldFp, err := os.Open(args[1])
+				if err != nil {
+					return err
+				}
+				defer oldFp.Close()
+				newFp, err := os.Open(args[2])
+				if err != nil {
+					return err
+				}
+				defer newFp.Close()
+				from = &backupFileSource{name: args[1], r: csv.NewReader(oldFp)}
+				to = &backupFileSource{name: args[2], r: csv.NewReader(newFp)}
+			default:
+				return utils.NewParseError("unknown diff type, should be ts or files")
+			}
+
+			output := [][]string{{"Key", "Change", "Old", "New"}}
+			shown := 0
+			stats, err := diffSources(from, to, func(k []byte, o, n *client.KV) {
+				if shown++; shown > diffMaxShown {
+					return
+				}
+				change := "changed"
+				switch {
+				case o == nil:
+					change = "added"
+				case n == nil:
+					change = "removed"
+				}
+				output = append(output, []string{utils.Bytes2ReadableStrLit(k), change, diffPreview(o), diffPreview(n)})
+			})
+			if total := stats.added + stats.removed + stats.changed + stats.unchanged; total >= 10000 {
+				fmt.Fprintln(os.Stderr)
+			}
+			if err != nil {
+				return err
+			}
+			if len(output) > 1 {
+				utils.PrintTable(output)
+			}
+			if shown > diffMaxShown {
+				fmt.Fprintf(os.Stderr, "%d more differences not shown\n", shown-diffMaxShown)
+			}
+			utils.Print(fmt.Sprintf("%d added, %d removed, %d changed, %d unchanged",
+				stats.added, stats.removed, stats.changed, stats.unchanged))
+			return nil
+		})
+	}
+}
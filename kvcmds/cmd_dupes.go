@@ -0,0 +1,205 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type DupesCmd struct{}
+
+var _ tcli.Cmd = DupesCmd{}
+
+func (c DupesCmd) Name() string    { return "dupes" }
+func (c DupesCmd) Alias() []string { return []string{"dupes"} }
+func (c DupesCmd) Help() string {
+	return `find values shared by multiple keys, use "dupes --help" for more details`
+}
+
+func (c DupesCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	dupes <key prefix | *> <options>
+Options:
+	--limit=<limit>, max keys to scan, default 0 (no limit)
+	--batch-size=<size>, default 1000
+	--top=<n>, show the n most duplicated values, default 20
+	--examples=<n>, example keys per value, default 3
+	--min-size=<n>, ignore values shorter than n bytes, default 1
+	--max-digests=<n>, max distinct values to track, default 10000000
+Description:
+	Values are compared by a 128-bit digest. The range is scanned twice: the
+	first pass counts digests only, the second one collects example keys of
+	duplicated values, so memory stays bounded by --max-digests.
+Examples:
+	dupes "user_"
+	dupes * --limit=1000000 --min-size=64
+`
+	return s
+}
+
+type valueDigest [16]byte
+
+func digestOf(v []byte) valueDigest {
+	var d valueDigest
+	h := fnv.New128a()
+	h.Write(v)
+	h.Sum(d[:0])
+	return d
+}
+
+type dupGroup struct {
+	count    int
+	size     int
+	preview  []byte
+	examples [][]byte
+}
+
+// previewValue keeps the head of a value for display
+func previewValue(v []byte) []byte {
+	const maxPreview = 32
+	if len(v) > maxPreview {
+		v = v[:maxPreview]
+	}
+	return append([]byte(nil), v...)
+}
+
+func (c DupesCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 1 {
+				if err := utils.SetOptByString(ic.Args[1:], opt); err != nil {
+					return err
+				}
+			}
+			limit := opt.GetInt(tcli.DupesOptLimit, 0)
+			batchSize := opt.GetInt(tcli.DupesOptBatchSize, 1000)
+			minSize := opt.GetInt(tcli.DupesOptMinSize, 1)
+			maxDigests := opt.GetInt(tcli.DupesOptMaxDigests, 10000000)
+			maxExamples := opt.GetInt(tcli.DupesOptExamples, 3)
+			if batchSize <= 0 || maxDigests <= 0 {
+				return errors.New("batch-size and max-digests should be greater than 0")
+			}
+
+			// pass 1: count digests
+			counts := make(map[valueDigest]int)
+			scanned := 0
+			err = scanPrefixBatches(prefix, false, batchSize, limit, func(kvs client.KVS) error {
+				for _, kv := range kvs {
+					scanned++
+					if len(kv.V) < minSize {
+						continue
+					}
+					d := digestOf(kv.V)
+					if _, ok := counts[d]; !ok && len(counts) >= maxDigests {
+						return fmt.Errorf("more than %d distinct values, narrow the prefix or use --limit / --max-digests", maxDigests)
+					}
+					counts[d]++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			groups := make(map[valueDigest]*dupGroup)
+			for d, cnt := range counts {
+				if cnt > 1 {
+					groups[d] = &dupGroup{}
+				}
+			}
+			counts = nil
+			if len(groups) == 0 {
+				fmt.Fprintf(os.Stderr, "%d keys scanned, no duplicated values\n", scanned)
+				return nil
+			}
+
+			// pass 2: collect keys of duplicated values
+			err = scanPrefixBatches(prefix, false, batchSize, limit, func(kvs client.KVS) error {
+				for _, kv := range kvs {
+					if len(kv.V) < minSize {
+						continue
+					}
+					g, ok := groups[digestOf(kv.V)]
+					if !ok {
+						continue
+					}
+					if g.count == 0 {
+						g.size = len(kv.V)
+						g.preview = previewValue(kv.V)
+					}
+					g.count++
+					if len(g.examples) < maxExamples {
+						g.examples = append(g.examples, kv.K)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			var sorted []*dupGroup
+			redundant := 0
+			for _, g := range groups {
+				// values may have changed between the two passes
+				if g.count < 2 {
+					continue
+				}
+				sorted = append(sorted, g)
+				redundant += (g.count - 1) * g.size
+			}
+			sort.Slice(sorted, func(i, j int) bool {
+				if sorted[i].count != sorted[j].count {
+					return sorted[i].count > sorted[j].count
+				}
+				return sorted[i].size > sorted[j].size
+			})
+			if top := opt.GetInt(tcli.DupesOptTop, 20); top > 0 && len(sorted) > top {
+				sorted = sorted[:top]
+			}
+
+			data := [][]string{{"Value", "Size", "Keys", "Examples"}}
+			for _, g := range sorted {
+				var examples []string
+				for _, k := range g.examples {
+					examples = append(examples, utils.Bytes2ReadableStrLit(k))
+				}
+				value := utils.Bytes2ReadableStrLit(g.preview)
+				if len(g.preview) < g.size {
+					value += "..."
+				}
+				data = append(data, []string{
+					value,
+					strconv.Itoa(g.size),
+					strconv.Itoa(g.count),
+					strings.Join(examples, " "),
+				})
+			}
+			utils.PrintTable(data)
+			fmt.Fprintf(os.Stderr, "%d keys scanned, %d duplicated values, %s redundant\n",
+				scanned, len(groups), humanSize(redundant))
+			return nil
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type EstimateCmd struct{}
+
+var _ tcli.Cmd = EstimateCmd{}
+
+func (c EstimateCmd) Name() string    { return "estimate" }
+func (c EstimateCmd) Alias() []string { return []string{"estimate"} }
+func (c EstimateCmd) Help() string {
+	return `estimate the number of keys from PD region statistics, use "estimate --help" for more details`
+}
+
+func (c EstimateCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	estimate count <key prefix | *> <options>
+	estimate count <start key> --end=<end key>
+Options:
+	--end=<end key>, estimate [start key, end key) instead of a prefix
+	--by=<store | zone>, split the estimate by the store or the zone label
+	                     of the region leaders, zone needs txn mode
+Description:
+	Sums the approximate key count TiKV reports to PD for every region of
+	the range, no key is scanned. PD only knows whole regions: the regions
+	at both ends are counted in full, so small ranges are overestimated,
+	and the statistics lag behind recent writes.
+Examples:
+	estimate count "user_"
+	estimate count *
+	estimate count "user_a" --end="user_m"
+	estimate count * --by=zone
+`
+	return s
+}
+
+func statsRow(stats *client.RegionStats) []string {
+	return []string{
+		strconv.Itoa(stats.Regions),
+		strconv.FormatInt(stats.ApproximateKeys, 10),
+		fmt.Sprintf("%d MiB", stats.ApproximateSize),
+	}
+}
+
+func leaderStoreIDs(stats client.RegionStats) []uint64 {
+	var ids []uint64
+	for id := range stats.Leaders {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (c EstimateCmd) printByStore(stats client.RegionStats) {
+	output := [][]string{{"Leader Store", "Regions", "Approximate Keys", "Approximate Size"}}
+	for _, id := range leaderStoreIDs(stats) {
+		store := strconv.FormatUint(id, 10)
+		if id == 0 {
+			store = "no leader"
+		}
+		output = append(output, append([]string{store}, statsRow(stats.Leaders[id])...))
+	}
+	utils.PrintTable(output)
+}
+
+func (c EstimateCmd) printByZone(stats client.RegionStats) error {
+	stores, err := client.GetTiKVClient().GetStores()
+	if err != nil {
+		return err
+	}
+	zones := make(map[string]string)
+	for _, store := range stores {
+		zones[store.ID] = store.Zone
+	}
+	byZone := make(map[string]*client.RegionStats)
+	var names []string
+	for _, id := range leaderStoreIDs(stats) {
+		zone, ok := zones[strconv.FormatUint(id, 10)]
+		switch {
+		case id == 0:
+			zone = "no leader"
+		case !ok || zone == "":
+			zone = "unknown"
+		}
+		z, ok := byZone[zone]
+		if !ok {
+			z = &client.RegionStats{}
+			byZone[zone] = z
+			names = append(names, zone)
+		}
+		s := stats.Leaders[id]
+		z.Regions += s.Regions
+		z.ApproximateKeys += s.ApproximateKeys
+		z.ApproximateSize += s.ApproximateSize
+	}
+	sort.Strings(names)
+	output := [][]string{{"Leader Zone", "Regions", "Approximate Keys", "Approximate Size"}}
+	for _, zone := range names {
+		output = append(output, append([]string{zone}, statsRow(byZone[zone])...))
+	}
+	utils.PrintTable(output)
+	return nil
+}
+
+func (c EstimateCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			if ic.Args[0] != "count" {
+				return utils.NewParseError("unknown estimate type, should be count")
+			}
+			rc, ok := client.GetTiKVClient().(client.RegionStatsClient)
+			if !ok {
+				return errors.New("estimate needs PD region statistics, only available with a TiKV cluster")
+			}
+			startKey, err := utils.GetStringLit(ic.RawArgs[2])
+			if err != nil {
+				return err
+			}
+			// options come from the raw args, shell-splitting would strip
+			// the quotes of a h'...' end key
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(ic.RawArgs[3:], opt); err != nil {
+				return err
+			}
+
+			var endKey []byte
+			if end := opt.GetString(tcli.EstimateOptEnd, ""); end != "" {
+				if endKey, err = utils.GetStringLit(end); err != nil {
+					return err
+				}
+			} else if string(startKey) == "*" {
+				startKey = []byte{}
+			} else {
+				endKey = utils.PrefixNextKey(startKey)
+			}
+
+			by := opt.GetString(tcli.EstimateOptBy, "")
+			if by != "" && by != "store" && by != "zone" {
+				return utils.NewParseError("invalid --by %q, should be store or zone", by)
+			}
+			if by == "zone" && client.GetTiKVClient().GetClientMode() != client.TXN_CLIENT {
+				return errors.New("store labels are only available in txn mode")
+			}
+
+			stats, err := rc.GetRegionStats(context.TODO(), startKey, endKey)
+			if err != nil {
+				return err
+			}
+			switch by {
+			case "store":
+				c.printByStore(stats)
+			case "zone":
+				if err := c.printByZone(stats); err != nil {
+					return err
+				}
+			default:
+				utils.PrintTable([][]string{
+					{"Regions", "Approximate Keys", "Approximate Size"},
+					statsRow(&stats),
+				})
+			}
+			fmt.Fprintln(os.Stderr, "Approximate, regions at both ends of the range are counted in full")
+			return nil
+		})
+	}
+}
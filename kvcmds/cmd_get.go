@@ -2,11 +2,15 @@ package kvcmds
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 
 	"github.com/c4pt0r/tcli"
 	"github.com/c4pt0r/tcli/utils"
 
 	"github.com/c4pt0r/tcli/client"
+	"github.com/magiconair/properties"
 )
 
 type GetCmd struct{}
@@ -16,11 +20,19 @@ var _ tcli.Cmd = GetCmd{}
 func (c GetCmd) Name() string    { return "get" }
 func (c GetCmd) Alias() []string { return []string{"g"} }
 func (c GetCmd) Help() string {
-	return `get [key]`
+	return `get [key] <options>`
 }
 
 func (c GetCmd) LongHelp() string {
-	return c.Help()
+	s := c.Help()
+	s += `
+Options:
+	--raw, write the value bytes only, without table or newline
+Examples:
+	get "user_1"
+	get "avatar_1" --raw \o avatar_1.png
+`
+	return s
 }
 
 func (c GetCmd) Handler() func(ctx context.Context) {
@@ -37,13 +49,113 @@ func (c GetCmd) Handler() func(ctx context.Context) {
 			if err != nil {
 				return err
 			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 1 {
+				if err := utils.SetOptByString(ic.Args[1:], opt); err != nil {
+					return err
+				}
+			}
 			kv, err := client.GetTiKVClient().Get(context.TODO(), client.Key(k))
 			if err != nil {
 				return err
 			}
+			if opt.GetBool(tcli.GetOptRaw, false) {
+				_, err = os.Stdout.Write(kv.V)
+				return err
+			}
 			kvs := []client.KV{kv}
 			client.KVS(kvs).Print()
 			return nil
 		})
 	}
 }
+
+type GetManyCmd struct{}
+
+var _ tcli.Cmd = GetManyCmd{}
+
+func (c GetManyCmd) Name() string    { return "getmany" }
+func (c GetManyCmd) Alias() []string { return []string{"mget"} }
+func (c GetManyCmd) Help() string {
+	return `get several keys in one batch, usage: getmany <key> [key...]`
+}
+
+func (c GetManyCmd) LongHelp() string {
+	return c.Help()
+}
+
+func (c GetManyCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			var keys []client.Key
+			for _, s := range ic.RawArgs[1:] {
+				k, err := utils.GetStringLit(s)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, k)
+			}
+			kvs, err := client.GetTiKVClient().BatchGet(context.TODO(), keys)
+			if err != nil {
+				return err
+			}
+			kvs.Print()
+			if missing := len(keys) - len(kvs); missing > 0 {
+				fmt.Fprintf(os.Stderr, "%d of %d keys not found\n", missing, len(keys))
+			}
+			return nil
+		})
+	}
+}
+
+type ExistsCmd struct{}
+
+var _ tcli.Cmd = ExistsCmd{}
+
+func (c ExistsCmd) Name() string    { return "exists" }
+func (c ExistsCmd) Alias() []string { return []string{"exists"} }
+func (c ExistsCmd) Help() string {
+	return `print true if the key exists, false otherwise, usage: exists <key>`
+}
+
+func (c ExistsCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Description:
+	A missing key fails the command, so a script exits with code 1.
+Examples:
+	echo 'exists "user_1"' | tcli && echo found
+`
+	return s
+}
+
+func (c ExistsCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			k, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			kvs, err := client.GetTiKVClient().BatchGet(context.TODO(), []client.Key{k})
+			if err != nil {
+				return err
+			}
+			if len(kvs) == 0 {
+				utils.Print("false")
+				return errors.New("key does not exist")
+			}
+			utils.Print("true")
+			return nil
+		})
+	}
+}
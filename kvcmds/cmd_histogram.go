@@ -0,0 +1,233 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type HistogramCmd struct{}
+
+var _ tcli.Cmd = HistogramCmd{}
+
+func (c HistogramCmd) Name() string    { return "histogram" }
+func (c HistogramCmd) Alias() []string { return []string{"hist"} }
+func (c HistogramCmd) Help() string {
+	return `show the distribution of value or key sizes, use "histogram --help" for more details`
+}
+
+func (c HistogramCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	histogram <vsize | ksize> <key prefix | *> <options>
+Options:
+	--limit=<limit>, max keys to scan, default 0 (no limit)
+	--batch-size=<size>, default 1000
+	--top=<n>, show the n largest entries, default 5
+Examples:
+	# value sizes of all keys with prefix "user_"
+	histogram vsize "user_"
+
+	# key sizes of the first 100000 keys
+	histogram ksize * --limit=100000
+Alias:
+	hist
+`
+	return s
+}
+
+// sizeHistogram buckets sizes by powers of two: 0, 1, [2, 4), [4, 8), ...
+// Exact counts per distinct size are kept as well for the percentiles,
+// there are far fewer distinct sizes than keys in practice.
+type sizeHistogram struct {
+	buckets []int
+	sizes   map[int]int
+	count   int
+	total   int64
+	top     []sizedKey // largest entries, descending by size
+	topN    int
+}
+
+type sizedKey struct {
+	key  client.Key
+	size int
+}
+
+func newSizeHistogram(topN int) *sizeHistogram {
+	return &sizeHistogram{
+		sizes: make(map[int]int),
+		topN:  topN,
+	}
+}
+
+func (h *sizeHistogram) add(size int, k client.Key) {
+	b := bits.Len(uint(size))
+	for len(h.buckets) <= b {
+		h.buckets = append(h.buckets, 0)
+	}
+	h.buckets[b]++
+	h.sizes[size]++
+	h.count++
+	h.total += int64(size)
+
+	if h.topN <= 0 {
+		return
+	}
+	if len(h.top) == h.topN && size <= h.top[len(h.top)-1].size {
+		return
+	}
+	i := sort.Search(len(h.top), func(i int) bool { return h.top[i].size < size })
+	h.top = append(h.top, sizedKey{})
+	copy(h.top[i+1:], h.top[i:])
+	h.top[i] = sizedKey{key: k, size: size}
+	if len(h.top) > h.topN {
+		h.top = h.top[:h.topN]
+	}
+}
+
+// percentile returns the smallest size s that p percent of entries are <= s
+func (h *sizeHistogram) percentile(distinct []int, p float64) int {
+	if h.count == 0 {
+		return 0
+	}
+	target := int(float64(h.count)*p/100 + 0.5)
+	if target < 1 {
+		target = 1
+	}
+	seen := 0
+	for _, size := range distinct {
+		seen += h.sizes[size]
+		if seen >= target {
+			return size
+		}
+	}
+	return distinct[len(distinct)-1]
+}
+
+func bucketRange(b int) string {
+	if b <= 1 {
+		return humanSize(b)
+	}
+	lo := 1 << (b - 1)
+	return fmt.Sprintf("[%s, %s)", humanSize(lo), humanSize(lo<<1))
+}
+
+func humanSize(n int) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	}
+}
+
+func (h *sizeHistogram) print(what string) {
+	if h.count == 0 {
+		utils.Print("No keys found")
+		return
+	}
+	data := [][]string{{"Size", "Count", "Percent", "Cumulative"}}
+	cumulative := 0
+	for b, cnt := range h.buckets {
+		if cnt == 0 {
+			continue
+		}
+		cumulative += cnt
+		data = append(data, []string{
+			bucketRange(b),
+			strconv.Itoa(cnt),
+			fmt.Sprintf("%.2f%%", float64(cnt)*100/float64(h.count)),
+			fmt.Sprintf("%.2f%%", float64(cumulative)*100/float64(h.count)),
+		})
+	}
+	utils.PrintTable(data)
+
+	distinct := make([]int, 0, len(h.sizes))
+	for size := range h.sizes {
+		distinct = append(distinct, size)
+	}
+	sort.Ints(distinct)
+	stats := [][]string{
+		{"Stat", "Value"},
+		{"Keys", strconv.Itoa(h.count)},
+		{"Total " + what, humanSize(int(h.total))},
+		{"Avg", fmt.Sprintf("%.1f B", float64(h.total)/float64(h.count))},
+		{"Min", strconv.Itoa(distinct[0])},
+		{"P50", strconv.Itoa(h.percentile(distinct, 50))},
+		{"P90", strconv.Itoa(h.percentile(distinct, 90))},
+		{"P99", strconv.Itoa(h.percentile(distinct, 99))},
+		{"Max", strconv.Itoa(distinct[len(distinct)-1])},
+	}
+	utils.PrintTable(stats)
+
+	if len(h.top) > 0 {
+		top := [][]string{{"Key", "Size"}}
+		for _, e := range h.top {
+			top = append(top, []string{utils.Bytes2ReadableStrLit(e.key), strconv.Itoa(e.size)})
+		}
+		utils.PrintTable(top)
+	}
+}
+
+func (c HistogramCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			kind := ic.Args[0]
+			if kind != "vsize" && kind != "ksize" {
+				return errors.New("unknown histogram type, should be vsize or ksize")
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[2])
+			if err != nil {
+				return err
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 2 {
+				if err := utils.SetOptByString(ic.Args[2:], opt); err != nil {
+					return err
+				}
+			}
+			batchSize := opt.GetInt(tcli.HistogramOptBatchSize, 1000)
+			if batchSize <= 0 {
+				return errors.New("batch-size should be greater than 0")
+			}
+
+			h := newSizeHistogram(opt.GetInt(tcli.HistogramOptTop, 5))
+			keyOnly := kind == "ksize"
+			err = scanPrefixBatches(prefix, keyOnly, batchSize, opt.GetInt(tcli.HistogramOptLimit, 0), func(kvs client.KVS) error {
+				for _, kv := range kvs {
+					if keyOnly {
+						h.add(len(kv.K), kv.K)
+					} else {
+						h.add(len(kv.V), kv.K)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if keyOnly {
+				h.print("Key Size")
+			} else {
+				h.print("Value Size")
+			}
+			return nil
+		})
+	}
+}
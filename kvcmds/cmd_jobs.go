@@ -0,0 +1,151 @@
+package kvcmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/ishell"
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// runBulk runs fn in the foreground with a nil job, or starts it as a
+// background job if bg is set
+func runBulk(ic *ishell.Context, bg bool, fn func(ctx context.Context, job *utils.Job) error) error {
+	if !bg {
+		return fn(context.TODO(), nil)
+	}
+	job := utils.StartJob(strings.Join(ic.RawArgs, " "), fn)
+	utils.Print(fmt.Sprintf("Started job %d, check it with \"job status %d\"", job.ID, job.ID))
+	return nil
+}
+
+type JobsCmd struct{}
+
+var _ tcli.Cmd = JobsCmd{}
+
+func (c JobsCmd) Name() string    { return "jobs" }
+func (c JobsCmd) Alias() []string { return []string{"jobs"} }
+func (c JobsCmd) Help() string {
+	return "list the background jobs of the session"
+}
+
+func (c JobsCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	jobs
+Description:
+	loadcsv, backup and delp started with --bg run in the background, the
+	shell stays usable meanwhile. sys.job_rate_limit caps every job to that
+	many keys per second, 0 (the default) means no limit:
+	sysvar sys.job_rate_limit="5000"
+See also:
+	job status <id>, job cancel <id>
+`
+	return s
+}
+
+func (c JobsCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			output := [][]string{{"ID", "State", "Keys", "Elapsed", "Command"}}
+			for _, job := range utils.ListJobs() {
+				st := job.Status()
+				output = append(output, []string{
+					strconv.Itoa(job.ID),
+					string(st.State),
+					strconv.FormatInt(st.Keys, 10),
+					st.Elapsed.Round(time.Second).String(),
+					job.Cmd,
+				})
+			}
+			utils.PrintTable(output)
+			return nil
+		})
+	}
+}
+
+type JobCmd struct{}
+
+var _ tcli.Cmd = JobCmd{}
+
+func (c JobCmd) Name() string    { return "job" }
+func (c JobCmd) Alias() []string { return []string{"job"} }
+func (c JobCmd) Help() string {
+	return `show or cancel a background job, use "job --help" for more details`
+}
+
+func (c JobCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	job status <id>
+	job cancel <id>
+Description:
+	status shows the progress of a job and the last lines it printed.
+	cancel stops a job at the end of its current batch, an interrupted
+	loadcsv or backup continues with --resume.
+Examples:
+	job status 1
+	job cancel 1
+`
+	return s
+}
+
+func (c JobCmd) status(job *utils.Job) {
+	st := job.Status()
+	output := [][]string{
+		{"Job", "Value"},
+		{"ID", strconv.Itoa(job.ID)},
+		{"Command", job.Cmd},
+		{"State", string(st.State)},
+		{"Keys", strconv.FormatInt(st.Keys, 10)},
+		{"Elapsed", st.Elapsed.Round(time.Millisecond).String()},
+	}
+	if secs := st.Elapsed.Seconds(); secs > 0 {
+		output = append(output, []string{"Keys/s", strconv.FormatFloat(float64(st.Keys)/secs, 'f', 0, 64)})
+	}
+	if st.Err != nil {
+		output = append(output, []string{"Error", st.Err.Error()})
+	}
+	utils.PrintTable(output)
+	for _, line := range st.Log {
+		utils.Print(line)
+	}
+}
+
+func (c JobCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			id, err := strconv.Atoi(ic.Args[1])
+			if err != nil {
+				return utils.NewParseError("invalid job id %q", ic.Args[1])
+			}
+			job, ok := utils.GetJob(id)
+			if !ok {
+				return fmt.Errorf("no such job: %d", id)
+			}
+			switch ic.Args[0] {
+			case "status":
+				c.status(job)
+				return nil
+			case "cancel":
+				if err := job.Cancel(); err != nil {
+					return err
+				}
+				utils.Print(fmt.Sprintf("Cancelling job %d", id))
+				return nil
+			}
+			return utils.NewParseError("usage: job status | cancel <id>")
+		})
+	}
+}
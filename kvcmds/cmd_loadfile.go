@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"strconv"
 
 	"github.com/c4pt0r/tcli/utils"
 
@@ -35,6 +36,12 @@ Alias:
 	lcsv
 Options:
 	--batch-size=<size>: int, how many records in one tikv transaction, default: 1000
+	--skip-rows=<n>: int, skip the first n rows, e.g. a header
+	--resume: continue an interrupted load of the file
+	--bg: load in the background, see "jobs"
+Description:
+	The progress is recorded in [filename].manifest after every batch, with
+	the key count and checksum of the kv pairs loaded, see "manifest".
 Examples:
 	# load csv file to tikv
 	loadcsv sample.csv 
@@ -44,17 +51,42 @@ Examples:
 
 	# load csv file to tikv with key prefix and skip first row (header)
 	loadcsv sample.csv "prefix_" --batch-size=100 --skip-rows=1
+
+	# continue after an interruption, with the same prefix and skip-rows
+	loadcsv sample.csv "prefix_" --skip-rows=1 --resume
 `
 	return s
 }
 
-func (c LoadCsvCmd) processCSV(prop *properties.Properties, rc io.Reader, keyPrefix []byte) error {
+// putBatch writes a batch and records it in the manifest, records is the
+// number of csv records consumed so far
+func (c LoadCsvCmd) putBatch(manifest *utils.JobManifest, batch []client.KV, records int64) error {
+	err := client.GetTiKVClient().BatchPut(context.TODO(), batch)
+	if err != nil {
+		return err
+	}
+	for _, kv := range batch {
+		manifest.AddPair(kv.K, kv.V)
+	}
+	manifest.Records = records
+	return manifest.Save()
+}
+
+// processCSV loads the records of rc, a nil job means the load runs in the
+// foreground
+func (c LoadCsvCmd) processCSV(ctx context.Context, job *utils.Job, prop *properties.Properties, rc io.Reader,
+	keyPrefix []byte, manifest *utils.JobManifest) error {
 	r := csv.NewReader(rc)
 	var cnt int
 	var batch []client.KV
 
 	batchSize := prop.GetInt(tcli.LoadFileOptBatchSize, 1000)
-	skips := prop.GetInt(tcli.LoadFileoptSkipRows, 0)
+	skips := int64(prop.GetInt(tcli.LoadFileoptSkipRows, 0))
+	// records done by the previous runs are skipped too
+	if manifest.Records > skips {
+		skips = manifest.Records
+	}
+	var records int64
 	for {
 		rawRec, err := r.Read()
 		if err != nil {
@@ -63,8 +95,8 @@ func (c LoadCsvCmd) processCSV(prop *properties.Properties, rc io.Reader, keyPre
 			}
 			return err
 		}
-		if skips > 0 {
-			skips--
+		records++
+		if records <= skips {
 			continue
 		}
 		if len(rawRec) != 2 {
@@ -87,13 +119,15 @@ func (c LoadCsvCmd) processCSV(prop *properties.Properties, rc io.Reader, keyPre
 		})
 		if len(batch) == batchSize {
 			// do insert
-			err := client.GetTiKVClient().BatchPut(context.TODO(), batch)
-			if err != nil {
+			if err := c.putBatch(manifest, batch, records); err != nil {
 				return err
 			}
 			// Show progress
 			progress := rc.(*utils.ProgressReader).GetProgress() * 100
-			utils.Print(fmt.Sprintf("Progress: %d%% Count: %d Last Key: %s", int(progress), cnt, k))
+			job.Print(fmt.Sprintf("Progress: %d%% Count: %d Last Key: %s", int(progress), cnt, k))
+			if err := job.Done(ctx, len(batch)); err != nil {
+				return err
+			}
 			// clean buffer
 			batch = nil
 		}
@@ -101,15 +135,43 @@ func (c LoadCsvCmd) processCSV(prop *properties.Properties, rc io.Reader, keyPre
 	// may have last batch
 	if len(batch) > 0 {
 		// do insert
-		err := client.GetTiKVClient().BatchPut(context.TODO(), batch)
-		if err != nil {
+		if err := c.putBatch(manifest, batch, records); err != nil {
 			return err
 		}
 	}
-	utils.Print(fmt.Sprintf("Done, affected records: %d", cnt))
+	manifest.Records = records
+	manifest.Done = true
+	if err := manifest.Save(); err != nil {
+		return err
+	}
+	job.Print(fmt.Sprintf("Done, affected records: %d", cnt))
 	return nil
 }
 
+// loadCsvManifest returns the manifest of a new load of csvFile, or of the
+// interrupted load to resume
+func loadCsvManifest(csvFile string, settings map[string]string, resume bool) (*utils.JobManifest, error) {
+	manifest, err := utils.LoadJobManifest(csvFile)
+	if err != nil {
+		return nil, err
+	}
+	if resume {
+		if manifest == nil {
+			return nil, fmt.Errorf("no manifest found for %s, can't resume", csvFile)
+		}
+		if err := manifest.CheckResume("loadcsv", settings); err != nil {
+			return nil, err
+		}
+		utils.Print(fmt.Sprintf("Resume loading after %d records", manifest.Records))
+		return manifest, nil
+	}
+	if manifest != nil && !manifest.Done {
+		return nil, fmt.Errorf("an interrupted %s of %s exists, continue it with --resume or remove %s",
+			manifest.Job, csvFile, utils.ManifestPath(csvFile))
+	}
+	return utils.NewJobManifest("loadcsv", csvFile, settings), nil
+}
+
 func (c LoadCsvCmd) Handler() func(ctx context.Context) {
 	return func(ctx context.Context) {
 		utils.OutputWithElapse(func() error {
@@ -140,21 +202,36 @@ func (c LoadCsvCmd) Handler() func(ctx context.Context) {
 
 			// set prop
 			prop := properties.NewProperties()
-			if len(ic.Args) > 2 {
-				err = utils.SetOptByString(flags, prop)
-				if err != nil {
-					return nil
-				}
+			if err := utils.SetOptByString(flags, prop); err != nil {
+				return err
 			}
 			// open file for read
 			fp, rdr, err := utils.OpenFileToProgressReader(csvFile)
 			if err != nil {
 				return err
 			}
-			defer fp.Close()
+			settings := map[string]string{
+				"prefix":    utils.Bytes2ReadableStrLit(keyPrefix),
+				"skip-rows": strconv.Itoa(prop.GetInt(tcli.LoadFileoptSkipRows, 0)),
+				"cluster":   client.GetTiKVClient().GetClusterID(),
+			}
+			manifest, err := loadCsvManifest(csvFile, settings, prop.GetBool(tcli.LoadFileOptResume, false))
+			if err != nil {
+				fp.Close()
+				return err
+			}
+			manifest.StartRun()
+			if err := manifest.Save(); err != nil {
+				fp.Close()
+				return err
+			}
 			// TODO should validate first
 			// TODO set batch size
-			return c.processCSV(prop, rdr, keyPrefix)
+			run := func(ctx context.Context, job *utils.Job) error {
+				defer fp.Close()
+				return c.processCSV(ctx, job, prop, rdr, keyPrefix, manifest)
+			}
+			return runBulk(ic, prop.GetBool(tcli.LoadFileOptBackground, false), run)
 		})
 	}
 }
@@ -0,0 +1,80 @@
+package kvcmds
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/abiosoft/ishell"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// runLoadCsv runs loadcsv with args and returns its error
+func runLoadCsv(args ...string) error {
+	ic := &ishell.Context{Args: args, RawArgs: append([]string{"loadcsv"}, args...)}
+	LoadCsvCmd{}.Handler()(context.WithValue(context.TODO(), "ishell", ic))
+	return utils.TakeLastCmdError()
+}
+
+func TestLoadCsvFlagsWithoutPrefix(t *testing.T) {
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	muteStdout(t)
+	dir := t.TempDir()
+	writeCsv := func(name, data string) string {
+		fname := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(fname, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fname
+	}
+
+	withHeader := writeCsv("header.csv", "key,value\nlcsv_h,1\n")
+	if err := runLoadCsv(withHeader, "--skip-rows=1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// an interrupted load of the first record
+	resumed := writeCsv("resume.csv", "lcsv_a,1\nlcsv_b,2\nlcsv_c,3\n")
+	settings := map[string]string{
+		"prefix":    utils.Bytes2ReadableStrLit(nil),
+		"skip-rows": "0",
+		"cluster":   client.GetTiKVClient().GetClusterID(),
+	}
+	manifest := utils.NewJobManifest("loadcsv", resumed, settings)
+	manifest.Records = 1
+	manifest.StartRun()
+	if err := manifest.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLoadCsv(resumed); err == nil {
+		t.Fatal("a new load should be refused while an interrupted one exists")
+	}
+	if err := runLoadCsv(resumed, "--resume"); err != nil {
+		t.Fatalf("resume without prefix: %v", err)
+	}
+	if manifest, err := utils.LoadJobManifest(resumed); err != nil || !manifest.Done {
+		t.Errorf("manifest should be done after the resumed load: %+v, %v", manifest, err)
+	}
+
+	tests := []struct {
+		key    string
+		exists bool
+	}{
+		{"key", false},
+		{"lcsv_h", true},
+		// loaded by the interrupted run
+		{"lcsv_a", false},
+		{"lcsv_b", true},
+		{"lcsv_c", true},
+	}
+	for _, tt := range tests {
+		_, err := client.GetTiKVClient().Get(context.TODO(), client.Key(tt.key))
+		if (err == nil) != tt.exists {
+			t.Errorf("key %s: got %v, want exists %v", tt.key, err, tt.exists)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package kvcmds
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+type ManifestCmd struct{}
+
+var _ tcli.Cmd = ManifestCmd{}
+
+func (c ManifestCmd) Name() string    { return "manifest" }
+func (c ManifestCmd) Alias() []string { return []string{"manifest"} }
+func (c ManifestCmd) Help() string {
+	return `show or verify the manifest of a backup or loadcsv job, use "manifest --help" for more details`
+}
+
+func (c ManifestCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	manifest show <data file>
+	manifest verify <data file>
+Description:
+	backup and loadcsv record their progress in <data file>.manifest: the
+	settings, the key ranges done by every run, the key count and a CRC32
+	of the kv pairs. An interrupted job continues with --resume.
+
+	verify checks a backup file against its manifest, or reads the keys
+	loaded from a csv file back from the store and checks their values,
+	keys written again after the load are reported as mismatches.
+Examples:
+	manifest show backup.csv
+	manifest verify backup.csv
+`
+	return s
+}
+
+func (c ManifestCmd) show(m *utils.JobManifest) {
+	output := [][]string{
+		{"Manifest", "Value"},
+		{"Job", m.Job},
+		{"File", m.File},
+		{"Done", strconv.FormatBool(m.Done)},
+		{"Keys", strconv.FormatInt(m.Keys, 10)},
+		{"Checksum", fmt.Sprintf("%08x", m.Checksum)},
+		{"Updated At", m.UpdatedAt.Format("2006-01-02 15:04:05")},
+	}
+	for _, k := range []string{"prefix", "skip-rows", "cluster"} {
+		if v, ok := m.Settings[k]; ok {
+			output = append(output, []string{"Setting " + k, v})
+		}
+	}
+	utils.PrintTable(output)
+	ranges := [][]string{{"Run", "First Key", "Last Key", "Keys"}}
+	for i, r := range m.Ranges {
+		ranges = append(ranges, []string{strconv.Itoa(i + 1), r.FirstKey, r.LastKey, strconv.FormatInt(r.Keys, 10)})
+	}
+	utils.PrintTable(ranges)
+}
+
+// verifyBackup sums the rows of the backup file the manifest knows about
+func (c ManifestCmd) verifyBackup(m *utils.JobManifest) (int64, uint32, error) {
+	fp, err := os.Open(m.File)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer fp.Close()
+	r := csv.NewReader(io.LimitReader(fp, m.FileSize))
+	var keys int64
+	var checksum uint32
+	// the first row is the header
+	header := true
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return keys, checksum, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if header {
+			header = false
+			continue
+		}
+		if len(rec) != 2 {
+			return 0, 0, fmt.Errorf("invalid backup record: %v", rec)
+		}
+		k, err := utils.GetStringLit(rec[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		v, err := utils.GetStringLit(rec[1])
+		if err != nil {
+			return 0, 0, err
+		}
+		keys++
+		checksum = utils.ChecksumKV(checksum, k, v)
+	}
+}
+
+// verifyLoad reads the keys loaded from the csv file back from the store,
+// in file order, and sums them with their current values
+func (c ManifestCmd) verifyLoad(m *utils.JobManifest) (int64, uint32, error) {
+	if cluster := client.GetTiKVClient().GetClusterID(); m.Settings["cluster"] != cluster {
+		return 0, 0, fmt.Errorf("the file was loaded into %s, connected to %s", m.Settings["cluster"], cluster)
+	}
+	keyPrefix, err := utils.GetStringLit(m.Settings["prefix"])
+	if err != nil {
+		return 0, 0, err
+	}
+	skips, err := strconv.ParseInt(m.Settings["skip-rows"], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid skip-rows in manifest: %v", err)
+	}
+	fp, err := os.Open(m.File)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer fp.Close()
+	r := csv.NewReader(fp)
+
+	var keys int64
+	var checksum uint32
+	var batch []client.Key
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		kvs, err := client.GetTiKVClient().BatchGet(context.TODO(), batch)
+		if err != nil {
+			return err
+		}
+		values := make(map[string][]byte, len(kvs))
+		for _, kv := range kvs {
+			values[string(kv.K)] = kv.V
+		}
+		for _, k := range batch {
+			// missing keys are left out of the count and the checksum
+			if v, ok := values[string(k)]; ok {
+				keys++
+				checksum = utils.ChecksumKV(checksum, k, v)
+			}
+		}
+		batch = nil
+		return nil
+	}
+	for records := int64(1); records <= m.Records; records++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if records <= skips {
+			continue
+		}
+		if len(rec) != 2 {
+			return 0, 0, fmt.Errorf("invalid csv record: %v", rec)
+		}
+		k, _ := utils.GetStringLit(rec[0])
+		key := append(append([]byte{}, keyPrefix...), k...)
+		batch = append(batch, client.Key(key))
+		if len(batch) == 1000 {
+			if err := flush(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, 0, err
+	}
+	return keys, checksum, nil
+}
+
+func (c ManifestCmd) verify(m *utils.JobManifest) error {
+	var keys int64
+	var checksum uint32
+	var err error
+	switch m.Job {
+	case "backup":
+		keys, checksum, err = c.verifyBackup(m)
+	case "loadcsv":
+		keys, checksum, err = c.verifyLoad(m)
+	default:
+		return fmt.Errorf("unknown job %q in manifest", m.Job)
+	}
+	if err != nil {
+		return err
+	}
+	utils.PrintTable([][]string{
+		{"Check", "Manifest", "Actual"},
+		{"Keys", strconv.FormatInt(m.Keys, 10), strconv.FormatInt(keys, 10)},
+		{"Checksum", fmt.Sprintf("%08x", m.Checksum), fmt.Sprintf("%08x", checksum)},
+	})
+	if keys != m.Keys || checksum != m.Checksum {
+		return fmt.Errorf("%s doesn't match its manifest", m.File)
+	}
+	if !m.Done {
+		fmt.Fprintf(os.Stderr, "The %s is incomplete, resume it with --resume\n", m.Job)
+	}
+	return nil
+}
+
+func (c ManifestCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			m, err := utils.LoadJobManifest(ic.Args[1])
+			if err != nil {
+				return err
+			}
+			if m == nil {
+				return fmt.Errorf("no manifest found for %s", ic.Args[1])
+			}
+			switch ic.Args[0] {
+			case "show":
+				c.show(m)
+				return nil
+			case "verify":
+				return c.verify(m)
+			}
+			return utils.NewParseError("usage: manifest show | verify <data file>")
+		})
+	}
+}
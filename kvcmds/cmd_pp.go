@@ -0,0 +1,330 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+type PrettyPrintCmd struct{}
+
+var _ tcli.Cmd = PrettyPrintCmd{}
+
+func (c PrettyPrintCmd) Name() string    { return "pp" }
+func (c PrettyPrintCmd) Alias() []string { return []string{"pp"} }
+func (c PrettyPrintCmd) Help() string {
+	return `pretty-print a JSON value, optionally filtered by a jq-like expression, use "pp --help" for more details`
+}
+
+func (c PrettyPrintCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	pp <key> [expression]
+Expression:
+	.              the whole value
+	.name          the field "name" of an object
+	.["a name"]    the field "a name" of an object
+	.[n]           the n-th element of an array, negative counts from the end
+	.[]            every element of an array or value of an object
+	keys, length   the sorted keys, the length of a string, array or object
+	a | b          b applied to every output of a, paths can also be
+	               chained without pipes: .items[].name
+	Missing fields and out of range elements are null, like in jq.
+Examples:
+	pp user_1
+	pp user_1 .address.city
+	pp user_1 '.orders[-1].items[] | .sku'
+	pp user_1 '.tags | length'
+`
+	return s
+}
+
+type jqStepKind int
+
+const (
+	jqField jqStepKind = iota
+	jqIndex
+	jqIter
+	jqKeys
+	jqLength
+)
+
+type jqStep struct {
+	kind  jqStepKind
+	field string
+	index int
+}
+
+func isJqIdentChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// parseJqBracket parses the inside of [...] starting after the '[', returns
+// the step and the position after the ']'
+func parseJqBracket(expr string, i int) (jqStep, int, error) {
+	end := strings.IndexByte(expr[i:], ']')
+	if end < 0 {
+		return jqStep{}, 0, utils.NewParseError("missing ] in %q", expr)
+	}
+	if expr[i] == '"' {
+		// a quoted field name, the closing quote must be followed by ']'
+		q := i + 1
+		for q < len(expr) && expr[q] != '"' {
+			if expr[q] == '\\' {
+				q++
+			}
+			q++
+		}
+		if q >= len(expr) {
+			return jqStep{}, 0, utils.NewParseError("unterminated string in %q", expr)
+		}
+		name, err := strconv.Unquote(expr[i : q+1])
+		if err != nil {
+			return jqStep{}, 0, utils.NewParseError("invalid string in %q: %v", expr, err)
+		}
+		if q+1 >= len(expr) || expr[q+1] != ']' {
+			return jqStep{}, 0, utils.NewParseError("missing ] in %q", expr)
+		}
+		return jqStep{kind: jqField, field: name}, q + 2, nil
+	}
+	inner := strings.TrimSpace(expr[i : i+end])
+	if inner == "" {
+		return jqStep{kind: jqIter}, i + end + 1, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return jqStep{}, 0, utils.NewParseError("invalid index %q, should be an integer or a string", inner)
+	}
+	return jqStep{kind: jqIndex, index: n}, i + end + 1, nil
+}
+
+// parseJq parses the subset of jq described in the help of pp
+func parseJq(expr string) ([]jqStep, error) {
+	var steps []jqStep
+	i := 0
+	// a term starts the expression and follows every pipe
+	term := true
+	for i < len(expr) {
+		ch := expr[i]
+		switch {
+		case ch == ' ' || ch == '\t':
+			i++
+		case ch == '|':
+			if term {
+				return nil, utils.NewParseError("empty expression before | in %q", expr)
+			}
+			term = true
+			i++
+		case term && isJqIdentChar(ch):
+			j := i
+			for j < len(expr) && isJqIdentChar(expr[j]) {
+				j++
+			}
+			switch expr[i:j] {
+			case "keys":
+				steps = append(steps, jqStep{kind: jqKeys})
+			case "length":
+				steps = append(steps, jqStep{kind: jqLength})
+			default:
+				return nil, utils.NewParseError("unknown function %q, only keys and length are supported", expr[i:j])
+			}
+			term = false
+			i = j
+		case ch == '.' || (ch == '[' && !term):
+			if ch == '.' {
+				i++
+			}
+			term = false
+			switch {
+			case i < len(expr) && expr[i] == '[':
+				step, next, err := parseJqBracket(expr, i+1)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, step)
+				i = next
+			case i < len(expr) && isJqIdentChar(expr[i]):
+				j := i
+				for j < len(expr) && isJqIdentChar(expr[j]) {
+					j++
+				}
+				steps = append(steps, jqStep{kind: jqField, field: expr[i:j]})
+				i = j
+			}
+		default:
+			return nil, utils.NewParseError("unexpected %q in %q", ch, expr)
+		}
+	}
+	if term && len(expr) > 0 {
+		return nil, utils.NewParseError("empty expression after | in %q", expr)
+	}
+	return steps, nil
+}
+
+func jqTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func sortedJqKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyJqStep returns the outputs of step for the input v
+func applyJqStep(step jqStep, v interface{}) ([]interface{}, error) {
+	switch step.kind {
+	case jqField:
+		switch o := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case map[string]interface{}:
+			return []interface{}{o[step.field]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", jqTypeName(v), step.field)
+	case jqIndex:
+		switch a := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			i := step.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i < 0 || i >= len(a) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{a[i]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with a number", jqTypeName(v))
+	case jqIter:
+		switch o := v.(type) {
+		case []interface{}:
+			return o, nil
+		case map[string]interface{}:
+			var ret []interface{}
+			for _, k := range sortedJqKeys(o) {
+				ret = append(ret, o[k])
+			}
+			return ret, nil
+		}
+		return nil, fmt.Errorf("cannot iterate over %s", jqTypeName(v))
+	case jqKeys:
+		switch o := v.(type) {
+		case []interface{}:
+			ret := []interface{}{}
+			for i := range o {
+				ret = append(ret, json.Number(strconv.Itoa(i)))
+			}
+			return []interface{}{ret}, nil
+		case map[string]interface{}:
+			ret := []interface{}{}
+			for _, k := range sortedJqKeys(o) {
+				ret = append(ret, k)
+			}
+			return []interface{}{ret}, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", jqTypeName(v))
+	case jqLength:
+		switch o := v.(type) {
+		case nil:
+			return []interface{}{json.Number("0")}, nil
+		case string:
+			return []interface{}{json.Number(strconv.Itoa(len([]rune(o))))}, nil
+		case []interface{}:
+			return []interface{}{json.Number(strconv.Itoa(len(o)))}, nil
+		case map[string]interface{}:
+			return []interface{}{json.Number(strconv.Itoa(len(o)))}, nil
+		}
+		return nil, fmt.Errorf("%s has no length", jqTypeName(v))
+	}
+	return nil, errors.New("unknown expression")
+}
+
+func evalJq(steps []jqStep, v interface{}) ([]interface{}, error) {
+	values := []interface{}{v}
+	for _, step := range steps {
+		var next []interface{}
+		for _, v := range values {
+			out, err := applyJqStep(step, v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func (c PrettyPrintCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			k, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			// the expression may contain spaces, around pipes for instance
+			steps, err := parseJq(strings.Join(ic.Args[1:], " "))
+			if err != nil {
+				return err
+			}
+			kv, err := client.GetTiKVClient().Get(context.TODO(), client.Key(k))
+			if err != nil {
+				return err
+			}
+			// numbers are kept as written, big integers don't lose precision
+			dec := json.NewDecoder(bytes.NewReader(kv.V))
+			dec.UseNumber()
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return fmt.Errorf("the value of %s is not JSON: %v", utils.Bytes2ReadableStrLit(k), err)
+			}
+			results, err := evalJq(steps, v)
+			if err != nil {
+				return err
+			}
+			for _, r := range results {
+				var buf bytes.Buffer
+				enc := json.NewEncoder(&buf)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(r); err != nil {
+					return err
+				}
+				fmt.Print(buf.String())
+			}
+			return nil
+		})
+	}
+}
@@ -3,6 +3,9 @@ package kvcmds
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 
 	"github.com/c4pt0r/tcli"
 	"github.com/c4pt0r/tcli/utils"
@@ -17,11 +20,37 @@ var _ tcli.Cmd = PutCmd{}
 func (c PutCmd) Name() string    { return "put" }
 func (c PutCmd) Alias() []string { return []string{"put", "set"} }
 func (c PutCmd) Help() string {
-	return `put [key] [value]`
+	return `put [key] [value | @file | -]`
 }
 
 func (c PutCmd) LongHelp() string {
-	return c.Help()
+	s := c.Help()
+	s += `
+Value:
+	@<file>, read the value from a file, bytes are written as-is
+	-, read the value from stdin until EOF (ctrl-d)
+	quote the value to store a literal starting with @ or a single -
+Examples:
+	put "user_1" "{\"id\":1}"
+	put "user_1" @user_1.json
+	put "avatar_1" -
+`
+	return s
+}
+
+// readValue returns the value of a put, loaded from a file or stdin when
+// requested by the raw (unquoted) argument
+func readValue(raw string) ([]byte, error) {
+	switch {
+	case raw == "-":
+		return ioutil.ReadAll(os.Stdin)
+	case strings.HasPrefix(raw, "@"):
+		if len(raw) == 1 {
+			return nil, utils.NewParseError("missing file name after @")
+		}
+		return ioutil.ReadFile(raw[1:])
+	}
+	return utils.GetStringLit(raw)
 }
 
 func (c PutCmd) Handler() func(ctx context.Context) {
@@ -36,7 +65,7 @@ func (c PutCmd) Handler() func(ctx context.Context) {
 			if err != nil {
 				return err
 			}
-			v, err := utils.GetStringLit(ic.RawArgs[2])
+			v, err := readValue(ic.RawArgs[2])
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,153 @@
+package kvcmds
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type PutsCmd struct{}
+
+var _ tcli.Cmd = PutsCmd{}
+
+func (c PutsCmd) Name() string    { return "puts" }
+func (c PutsCmd) Alias() []string { return []string{"puts"} }
+func (c PutsCmd) Help() string {
+	return `put keys generated from a sequence, use "puts --help" for more details`
+}
+
+func (c PutsCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	puts seq <from>..<to> key <key template> value <value template> <options>
+Options:
+	--batch-size=<size>, how many keys in one transaction, default 1000
+Templates:
+	{{n}}, the sequence number
+	{{n:08}}, the sequence number padded with zeros to 8 digits
+Examples:
+	puts seq 1..1000 key 'user:{{n}}' value '{"id":{{n}}}'
+	puts seq 0..99999 key 'order_{{n:08}}' value 'v{{n}}' --batch-size=5000
+`
+	return s
+}
+
+var seqPlaceholder = regexp.MustCompile(`\{\{n(?::(\d+))?\}\}`)
+
+// seqTemplate is a key or value template, split around {{n}} placeholders
+type seqTemplate struct {
+	literals []string
+	formats  []string
+}
+
+func parseSeqTemplate(s string) seqTemplate {
+	var t seqTemplate
+	last := 0
+	for _, m := range seqPlaceholder.FindAllStringSubmatchIndex(s, -1) {
+		t.literals = append(t.literals, s[last:m[0]])
+		format := "%d"
+		if m[2] >= 0 {
+			format = "%" + s[m[2]:m[3]] + "d"
+		}
+		t.formats = append(t.formats, format)
+		last = m[1]
+	}
+	t.literals = append(t.literals, s[last:])
+	return t
+}
+
+func (t seqTemplate) expand(n int64) []byte {
+	var b strings.Builder
+	for i, format := range t.formats {
+		b.WriteString(t.literals[i])
+		fmt.Fprintf(&b, format, n)
+	}
+	b.WriteString(t.literals[len(t.literals)-1])
+	return []byte(b.String())
+}
+
+func parseSeqRange(s string) (int64, int64, error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, utils.NewParseError("invalid sequence %q, should be <from>..<to>", s)
+	}
+	from, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewParseError("invalid sequence start %q", parts[0])
+	}
+	to, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewParseError("invalid sequence end %q", parts[1])
+	}
+	if from > to {
+		return 0, 0, utils.NewParseError("sequence start %d is greater than end %d", from, to)
+	}
+	return from, to, nil
+}
+
+func (c PutsCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) == 0 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			// templates usually contain quotes and spaces, so the
+			// shell-split args are used instead of the raw ones
+			args, flags := utils.GetArgsAndOptionFlag(ic.Args)
+			if len(args) != 6 || args[0] != "seq" || args[2] != "key" || args[4] != "value" {
+				return utils.NewParseError("usage: puts seq <from>..<to> key <key template> value <value template>")
+			}
+			from, to, err := parseSeqRange(args[1])
+			if err != nil {
+				return err
+			}
+			keyTmpl := parseSeqTemplate(args[3])
+			if len(keyTmpl.formats) == 0 {
+				return utils.NewParseError("key template should contain {{n}}")
+			}
+			valueTmpl := parseSeqTemplate(args[5])
+
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(flags, opt); err != nil {
+				return err
+			}
+			batchSize := opt.GetInt(tcli.PutsOptBatchSize, 1000)
+			if batchSize <= 0 {
+				return utils.NewParseError("batch-size should be greater than 0")
+			}
+
+			var batch []client.KV
+			cnt := 0
+			total := float64(to) - float64(from) + 1
+			// n == to is checked before n++, so to may be math.MaxInt64
+			for n := from; ; n++ {
+				batch = append(batch, client.KV{K: keyTmpl.expand(n), V: valueTmpl.expand(n)})
+				if len(batch) == batchSize || n == to {
+					if err := client.GetTiKVClient().BatchPut(context.TODO(), batch); err != nil {
+						return err
+					}
+					cnt += len(batch)
+					batch = nil
+				}
+				if n == to {
+					break
+				}
+				if cnt > 0 && len(batch) == 0 {
+					utils.Print(fmt.Sprintf("Progress: %d%% Count: %d", int(float64(cnt)*100/total), cnt))
+				}
+			}
+			utils.Print(fmt.Sprintf("Done, affected records: %d", cnt))
+			return nil
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package kvcmds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+// saved queries are command line templates with {param} placeholders,
+// stored as a name => template JSON object in the config directory
+var (
+	savedQueryFile = "queries.json"
+	_reQueryParam  = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+func savedQueryPath() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, savedQueryFile), nil
+}
+
+func loadSavedQueries() (map[string]string, error) {
+	fname, err := savedQueryPath()
+	if err != nil {
+		return nil, err
+	}
+	queries := make(map[string]string)
+	buf, err := ioutil.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return queries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &queries); err != nil {
+		return nil, fmt.Errorf("invalid saved query file %s: %v", fname, err)
+	}
+	return queries, nil
+}
+
+func storeSavedQueries(queries map[string]string) error {
+	fname, err := savedQueryPath()
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fname, buf, 0644)
+}
+
+// expandQuery replaces {param} placeholders, every placeholder must be given
+func expandQuery(tmpl string, params map[string]string) (string, error) {
+	var missing []string
+	line := _reQueryParam.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing parameters: %s", strings.Join(missing, ", "))
+	}
+	return line, nil
+}
+
+type SaveQueryCmd struct{}
+
+var _ tcli.Cmd = SaveQueryCmd{}
+
+func (c SaveQueryCmd) Name() string    { return "save" }
+func (c SaveQueryCmd) Alias() []string { return []string{"save"} }
+func (c SaveQueryCmd) Help() string {
+	return `save a parameterized command as a named query, use "save --help" for more details`
+}
+
+func (c SaveQueryCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	save query <name> as '<command with {param} placeholders>'
+	save query <name> drop
+Examples:
+	save query cleanup as 'delp {prefix} --limit={limit}'
+	run cleanup prefix="user_" limit=100
+`
+	return s
+}
+
+func (c SaveQueryCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 3 || ic.Args[0] != "query" {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			name := ic.Args[1]
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return err
+			}
+			switch {
+			case ic.Args[2] == "drop":
+				if _, ok := queries[name]; !ok {
+					return errors.New("no such query")
+				}
+				delete(queries, name)
+			case ic.Args[2] == "as" && len(ic.Args) == 4:
+				queries[name] = ic.Args[3]
+			default:
+				utils.Print(c.LongHelp())
+				return errors.New("wrong format")
+			}
+			return storeSavedQueries(queries)
+		})
+	}
+}
+
+type RunQueryCmd struct{}
+
+var _ tcli.Cmd = RunQueryCmd{}
+
+func (c RunQueryCmd) Name() string    { return "run" }
+func (c RunQueryCmd) Alias() []string { return []string{"run"} }
+func (c RunQueryCmd) Help() string {
+	return `run a saved query, "run" without args lists saved queries`
+}
+
+func (c RunQueryCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	run
+	run <name> [param=value]...
+Examples:
+	run cleanup prefix="user_" limit=100
+	run cleanup prefix=h'7573' limit=100
+`
+	return s
+}
+
+func (c RunQueryCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ic := utils.ExtractIshellContext(ctx)
+		queries, err := loadSavedQueries()
+		if err == nil && len(ic.Args) < 1 {
+			data := [][]string{{"Name", "Query"}}
+			for name, tmpl := range queries {
+				data = append(data, []string{name, tmpl})
+			}
+			sort.Slice(data[1:], func(i, j int) bool { return data[i+1][0] < data[j+1][0] })
+			utils.PrintTable(data)
+			return
+		}
+		var line string
+		if err == nil {
+			line, err = c.expand(queries, ic.RawArgs[1:])
+		}
+		if err == nil {
+			utils.Print(line)
+			err = utils.RunCmdLine(line)
+		}
+		if err != nil {
+			utils.OutputWithElapse(func() error { return err })
+		}
+	}
+}
+
+// expand looks up the query and fills in params given as raw name=value
+// args, values are kept verbatim so string literals like h'..' still work
+func (c RunQueryCmd) expand(queries map[string]string, rawArgs []string) (string, error) {
+	tmpl, ok := queries[rawArgs[0]]
+	if !ok {
+		return "", errors.New("no such query")
+	}
+	params := make(map[string]string)
+	for _, arg := range rawArgs[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("wrong param format: [%s], should be name=value", arg)
+		}
+		params[parts[0]] = parts[1]
+	}
+	return expandQuery(tmpl, params)
+}
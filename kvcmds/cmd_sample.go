@@ -0,0 +1,107 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type SampleCmd struct{}
+
+var _ tcli.Cmd = SampleCmd{}
+
+func (c SampleCmd) Name() string    { return "sample" }
+func (c SampleCmd) Alias() []string { return []string{"sample"} }
+func (c SampleCmd) Help() string {
+	return `pick random keys of a prefix, use "sample --help" for more details`
+}
+
+func (c SampleCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	sample <key prefix | *> <n> <options>
+Options:
+	--batch-size=<size>, default 1000
+	--seed=<seed>, the random seed, default a new one every time
+	--flatten, a column for every top-level field of JSON object values
+Description:
+	The whole range is scanned once and a uniform sample of n keys is kept
+	(reservoir sampling), every key has the same chance to be picked
+	wherever it is in the range. Memory is bounded by n. The sample is
+	printed in key order, the same --seed picks the same keys as long as
+	the range doesn't change.
+Examples:
+	sample "user_" 10
+	sample * 100 --seed=42 --flatten
+`
+	return s
+}
+
+func (c SampleCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(ic.Args[1])
+			if err != nil || n <= 0 {
+				return utils.NewParseError("invalid sample size %q, should be greater than 0", ic.Args[1])
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 2 {
+				if err := utils.SetOptByString(ic.Args[2:], opt); err != nil {
+					return err
+				}
+			}
+			batchSize := opt.GetInt(tcli.SampleOptBatchSize, 1000)
+			if batchSize <= 0 {
+				return errors.New("batch-size should be greater than 0")
+			}
+			seed := opt.GetInt64(tcli.SampleOptSeed, time.Now().UnixNano())
+			rnd := rand.New(rand.NewSource(seed))
+
+			// algorithm R: the i-th key replaces a random sampled key with
+			// probability n / i
+			var sample client.KVS
+			scanned := 0
+			err = scanPrefixBatches(prefix, false, batchSize, 0, func(kvs client.KVS) error {
+				for _, kv := range kvs {
+					scanned++
+					if len(sample) < n {
+						sample = append(sample, kv)
+						continue
+					}
+					if j := rnd.Intn(scanned); j < n {
+						sample[j] = kv
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			sort.Slice(sample, func(i, j int) bool { return bytes.Compare(sample[i].K, sample[j].K) < 0 })
+			printScanResult(sample, opt)
+			fmt.Fprintf(os.Stderr, "%d of %d keys sampled, seed %d\n", len(sample), scanned, seed)
+			return nil
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package kvcmds
 
 import (
+	"bytes"
 	"context"
 	"strconv"
 
@@ -27,9 +28,13 @@ Usage:
 	scan <start key> <options>
 Options:
 	--limit=<limit>, default 100
-	--key-only=<true|false>, default false
+	--key-only=<true|false>, default false, --keys-only is an alias
 	--strict-prefix=<true|false>, default false
 	--count-only=<true|false>, default false
+	--end=<end key>, stop before the end key, or at it with --reverse
+	--reverse, scan backwards from the key before the start key,
+	  "" starts from the last key (not supported on TiKV)
+	--flatten, a column for every top-level field of JSON object values
 Examples:
 	# scan from "a", max 10 keys
 	scan "a" --limit=10
@@ -45,6 +50,17 @@ Examples:
 
 	scan "a" --limit=10 --strict-prefix --key-only=true
 	scan $head --limit=10 --key-only=true
+
+	# scan keys in ["a", "b")
+	scan "a" --end="b"
+
+	# the last 10 keys before "b", then down to "a"
+	scan "b" --reverse --limit=10
+	scan "b" --reverse --end="a"
+
+	# the fields of JSON values as columns
+	scan "user_" --limit=10 --flatten
+	scan "user_" --flatten --columns=key,value.name
 `
 	return s
 }
@@ -63,18 +79,19 @@ func (c ScanCmd) Handler() func(ctx context.Context) {
 			if err != nil {
 				return err
 			}
-			scanOpt := properties.NewProperties()
-			if len(ic.Args) > 1 {
-				err := utils.SetOptByString(ic.Args[1:], scanOpt)
-				if err != nil {
-					return err
-				}
+			scanOpt, err := scanOptsFromArgs(ic.RawArgs[2:])
+			if err != nil {
+				return err
 			}
-			kvs, _, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), startKey)
+			kvClient, err := client.GetScanClient()
 			if err != nil {
 				return err
 			}
-			kvs.Print()
+			kvs, _, err := kvClient.Scan(utils.ContextWithProp(context.TODO(), scanOpt), startKey)
+			if err != nil {
+				return err
+			}
+			printScanResult(kvs, scanOpt)
 			return nil
 		})
 	}
@@ -108,24 +125,50 @@ func (c ScanPrefixCmd) Handler() func(ctx context.Context) {
 			if err != nil {
 				return err
 			}
-			scanOpt := properties.NewProperties()
-			if len(ic.Args) > 1 {
-				err := utils.SetOptByString(ic.Args[1:], scanOpt)
-				if err != nil {
-					return err
-				}
+			scanOpt, err := scanOptsFromArgs(ic.RawArgs[2:])
+			if err != nil {
+				return err
 			}
 			scanOpt.Set(tcli.ScanOptStrictPrefix, "true")
-			kvs, _, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), startKey)
+			kvClient, err := client.GetScanClient()
+			if err != nil {
+				return err
+			}
+			kvs, _, err := kvClient.Scan(utils.ContextWithProp(context.TODO(), scanOpt), startKey)
 			if err != nil {
 				return err
 			}
-			kvs.Print()
+			printScanResult(kvs, scanOpt)
 			return nil
 		})
 	}
 }
 
+// scanOptsFromArgs parses the options of scan and scanp. The raw args are
+// used, shell-splitting would strip the quotes of a h'...' end key.
+func scanOptsFromArgs(args []string) (*properties.Properties, error) {
+	scanOpt := properties.NewProperties()
+	if err := utils.SetOptByString(args, scanOpt); err != nil {
+		return nil, err
+	}
+	if scanOpt.GetBool(tcli.ScanOptKeysOnly, false) {
+		scanOpt.Set(tcli.ScanOptKeyOnly, "true")
+	}
+	return scanOpt, nil
+}
+
+// printScanResult prints the keys and values of a scan, --flatten expands
+// JSON object values into columns
+func printScanResult(kvs client.KVS, scanOpt *properties.Properties) {
+	if scanOpt.GetBool(tcli.ScanOptFlatten, false) &&
+		!scanOpt.GetBool(tcli.ScanOptKeyOnly, false) &&
+		!scanOpt.GetBool(tcli.ScanOptCountOnly, false) {
+		kvs.PrintFlattened()
+		return
+	}
+	kvs.Print()
+}
+
 type HeadCmd struct{}
 
 var _ tcli.Cmd = HeadCmd{}
@@ -163,12 +206,42 @@ func (c HeadCmd) Handler() func(ctx context.Context) {
 			// set limit
 			scanOpt.Set(tcli.ScanOptLimit, ic.Args[0])
 			scanOpt.Set(tcli.ScanOptStrictPrefix, "false")
-			kvs, _, err := client.GetTiKVClient().Scan(utils.ContextWithProp(context.TODO(), scanOpt), []byte("\x00"))
+			kvClient, err := client.GetScanClient()
 			if err != nil {
 				return err
 			}
-			kvs.Print()
+			kvs, _, err := kvClient.Scan(utils.ContextWithProp(context.TODO(), scanOpt), []byte("\x00"))
+			if err != nil {
+				return err
+			}
+			printScanResult(kvs, scanOpt)
 			return nil
 		})
 	}
 }
+
+// scanPrefixBatches walks all keys with prefix (or all keys for "*" and
+// \x00) in batches of batchSize, calling fn for each batch. It stops after
+// limit keys, limit <= 0 means no limit.
+func scanPrefixBatches(prefix []byte, keyOnly bool, batchSize int, limit int, fn func(kvs client.KVS) error) error {
+	return scanPrefixBatchesFrom(prefix, nil, keyOnly, batchSize, limit, fn)
+}
+
+// scanPrefixBatchesFrom is scanPrefixBatches starting at from instead of
+// the first key of the prefix, a nil from starts at the prefix
+func scanPrefixBatchesFrom(prefix, from []byte, keyOnly bool, batchSize int, limit int, fn func(kvs client.KVS) error) error {
+	startKey := prefix
+	if string(prefix) == "*" || bytes.Equal(prefix, []byte("\x00")) {
+		prefix = nil
+		startKey = []byte("\x00")
+	}
+	if from != nil {
+		startKey = from
+	}
+	// all batches read the same cluster
+	kvClient, err := client.GetScanClient()
+	if err != nil {
+		return err
+	}
+	return client.ScanBatches(context.TODO(), kvClient, startKey, prefix, keyOnly, batchSize, limit, fn)
+}
@@ -0,0 +1,56 @@
+package kvcmds
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/c4pt0r/tcli/client"
+)
+
+func TestScanPrefixBatchesFrom(t *testing.T) {
+	putTestKeys(t, "scn_", 2500)
+	tests := []struct {
+		prefix, from string
+		batchSize    int
+		limit        int
+		want         int
+	}{
+		{"scn_", "", 1000, 0, 2500},
+		{"scn_", "", 7, 0, 2500},
+		{"scn_", "", 1000, 1500, 1500},
+		{"scn_", "", 1000, 1000, 1000},
+		{"scn_01", "", 100, 0, 1000},
+		{"scn_", "scn_02000", 1000, 0, 500},
+		{"scn_", "scn_02499x", 1000, 0, 0},
+		{"noscn_", "", 1000, 0, 0},
+		// the keys around the prefix are only read without one
+		{"*", "", 1000, 0, 2502},
+	}
+	for _, tt := range tests {
+		var from []byte
+		if tt.from != "" {
+			from = []byte(tt.from)
+		}
+		got := 0
+		var last []byte
+		err := scanPrefixBatchesFrom([]byte(tt.prefix), from, true, tt.batchSize, tt.limit, func(kvs client.KVS) error {
+			if len(kvs) > tt.batchSize {
+				t.Errorf("%+v: batch of %d keys", tt, len(kvs))
+			}
+			for _, kv := range kvs {
+				if last != nil && bytes.Compare(kv.K, last) <= 0 {
+					t.Errorf("%+v: %q after %q", tt, kv.K, last)
+				}
+				if tt.prefix != "*" && !bytes.HasPrefix(kv.K, []byte(tt.prefix)) {
+					t.Errorf("%+v: %q is out of the prefix", tt, kv.K)
+				}
+				last = kv.K
+			}
+			got += len(kvs)
+			return nil
+		})
+		if err != nil || got != tt.want {
+			t.Errorf("%+v: got %d keys, %v", tt, got, err)
+		}
+	}
+}
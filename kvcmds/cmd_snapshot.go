@@ -0,0 +1,126 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+type BeginCmd struct{}
+
+var _ tcli.Cmd = BeginCmd{}
+
+func (c BeginCmd) Name() string    { return "begin" }
+func (c BeginCmd) Alias() []string { return []string{"begin"} }
+func (c BeginCmd) Help() string {
+	return `pin the reads of the following commands to one timestamp, use "begin --help" for more details`
+}
+
+func (c BeginCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	begin snapshot [ts]
+Description:
+	Until "end", get, scan and the other commands reading keys read the
+	data as of ts, so a sequence of commands sees one consistent view. ts
+	is a TSO, a local time ("2006-01-02 15:04:05") or a duration before
+	now, it defaults to now. Read replicas are not used meanwhile.
+	Writes are not pinned: they are committed as usual and not seen by the
+	pinned reads. The snapshot must stay after the GC safe point, data
+	older than the GC life time of the cluster may be collected. Txn mode
+	only.
+Examples:
+	begin snapshot
+	begin snapshot 10m
+	begin snapshot "2026-10-17 08:00:00"
+`
+	return s
+}
+
+func (c BeginCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			if ic.Args[0] != "snapshot" {
+				return utils.NewParseError("usage: begin snapshot [ts]")
+			}
+			kvClient := client.GetTiKVClient()
+			sc, ok := kvClient.(client.SnapshotClient)
+			gc, ok2 := kvClient.(client.GCClient)
+			if !ok || !ok2 {
+				return errors.New("snapshots are only supported in txn mode")
+			}
+			if ts := sc.SnapshotTS(); ts != 0 {
+				return fmt.Errorf("reads are already pinned to %s, end the snapshot first", utils.FormatTS(ts))
+			}
+			now, err := gc.GetCurrentTS(context.TODO())
+			if err != nil {
+				return err
+			}
+			ts := now
+			if len(ic.Args) > 1 {
+				if ts, err = utils.ParseTS(ic.Args[1], now); err != nil {
+					return err
+				}
+				if ts > now {
+					return fmt.Errorf("%s is in the future", utils.FormatTS(ts))
+				}
+			}
+			safePoint, err := gc.GetGCSafePoint(context.TODO())
+			if err != nil {
+				return err
+			}
+			if ts <= safePoint {
+				return fmt.Errorf("%s is before the GC safe point %s", utils.FormatTS(ts), utils.FormatTS(safePoint))
+			}
+			if err := sc.SetSnapshotTS(ts); err != nil {
+				return err
+			}
+			utils.Print(fmt.Sprintf("Reads pinned to %s until \"end\"", utils.FormatTS(ts)))
+			return nil
+		})
+	}
+}
+
+type EndCmd struct{}
+
+var _ tcli.Cmd = EndCmd{}
+
+func (c EndCmd) Name() string    { return "end" }
+func (c EndCmd) Alias() []string { return []string{"end"} }
+func (c EndCmd) Help() string {
+	return `end the snapshot started by "begin snapshot", reads see the latest data again`
+}
+
+func (c EndCmd) LongHelp() string {
+	return c.Help()
+}
+
+func (c EndCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			sc, ok := client.GetTiKVClient().(client.SnapshotClient)
+			if !ok || sc.SnapshotTS() == 0 {
+				return errors.New("no snapshot to end")
+			}
+			ts := sc.SnapshotTS()
+			if err := sc.SetSnapshotTS(0); err != nil {
+				return err
+			}
+			age := time.Since(oracle.GetTimeFromTS(ts)).Round(time.Second)
+			utils.Print(fmt.Sprintf("Snapshot %s ended, it was %s old", utils.FormatTS(ts), age))
+			return nil
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package kvcmds
+
+import (
+	"context"
+	"errors"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+type TTLCmd struct{}
+
+var _ tcli.Cmd = TTLCmd{}
+
+func (c TTLCmd) Name() string    { return "ttl" }
+func (c TTLCmd) Alias() []string { return []string{"ttl"} }
+func (c TTLCmd) Help() string {
+	return `ttl [key], show the remaining time to live of a key (badger mode only)`
+}
+
+func (c TTLCmd) LongHelp() string {
+	return c.Help()
+}
+
+func (c TTLCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			k, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			ttlClient, ok := client.GetTiKVClient().(client.TTLClient)
+			if !ok {
+				return errors.New("current mode does not support ttl")
+			}
+			ttl, err := ttlClient.GetTTL(context.TODO(), client.Key(k))
+			if err != nil {
+				return err
+			}
+			ttlStr := "never expires"
+			if ttl > 0 {
+				ttlStr = ttl.String()
+			}
+			kvs := []client.KV{{K: k, V: []byte(ttlStr)}}
+			client.KVS(kvs).Print()
+			return nil
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package kvcmds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type VerifyCmd struct{}
+
+var _ tcli.Cmd = VerifyCmd{}
+
+func (c VerifyCmd) Name() string    { return "verify" }
+func (c VerifyCmd) Alias() []string { return []string{"verify"} }
+func (c VerifyCmd) Help() string {
+	return `compare the leader and follower replicas of a range, use "verify --help" for more details`
+}
+
+func (c VerifyCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	verify replicas <key prefix | *> <options>
+	verify replicas <start key> --end=<end key>
+Options:
+	--end=<end key>, verify [start key, end key) instead of a prefix
+	--batch-size=<n>, keys read per request, default 1000
+Description:
+	Reads the range at one timestamp from the region leaders and from the
+	followers, and reports the keys missing on either side or having
+	different values. Follower reads are consistent, any difference points
+	to a replication issue. client-go picks one follower of each region,
+	run it again to check other followers. Txn mode only.
+Examples:
+	verify replicas "user_"
+	verify replicas "user_a" --end="user_m"
+`
+	return s
+}
+
+const (
+	verifyDefaultBatchSize = 1000
+	// the mismatches printed, the others are only counted
+	verifyMaxShownMismatches = 100
+)
+
+func describeValue(kv *client.KV) string {
+	if kv == nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%d bytes", len(kv.V))
+}
+
+// compareReplicas merges the sorted leader and follower keys, keys after
+// bound are left to the next batch, a nil bound compares everything
+func compareReplicas(leader, follower client.KVS, bound []byte, onMismatch func(k []byte, l, f *client.KV)) int {
+	checked := 0
+	i, j := 0, 0
+	for i < len(leader) || j < len(follower) {
+		var l, f *client.KV
+		switch {
+		case j == len(follower):
+			l = &leader[i]
+		case i == len(leader):
+			f = &follower[j]
+		default:
+			switch cmp := bytes.Compare(leader[i].K, follower[j].K); {
+			case cmp < 0:
+				l = &leader[i]
+			case cmp > 0:
+				f = &follower[j]
+			default:
+				l, f = &leader[i], &follower[j]
+			}
+		}
+		var k []byte
+		if l != nil {
+			k = l.K
+		} else {
+			k = f.K
+		}
+		if bound != nil && bytes.Compare(k, bound) > 0 {
+			break
+		}
+		checked++
+		if l == nil || f == nil || !bytes.Equal(l.V, f.V) {
+			onMismatch(k, l, f)
+		}
+		if l != nil {
+			i++
+		}
+		if f != nil {
+			j++
+		}
+	}
+	return checked
+}
+
+func (c VerifyCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 2 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			if ic.Args[0] != "replicas" {
+				return utils.NewParseError("unknown verify type, should be replicas")
+			}
+			rc, ok := client.GetTiKVClient().(client.ReplicaReadClient)
+			if !ok {
+				return errors.New("verify replicas is only supported in txn mode")
+			}
+			startKey, err := utils.GetStringLit(ic.RawArgs[2])
+			if err != nil {
+				return err
+			}
+			// options come from the raw args, shell-splitting would strip
+			// the quotes of a h'...' end key
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(ic.RawArgs[3:], opt); err != nil {
+				return err
+			}
+			batchSize := opt.GetInt(tcli.VerifyOptBatchSize, verifyDefaultBatchSize)
+			if batchSize <= 0 {
+				return utils.NewParseError("batch size should be positive")
+			}
+			var endKey []byte
+			if end := opt.GetString(tcli.VerifyOptEnd, ""); end != "" {
+				if endKey, err = utils.GetStringLit(end); err != nil {
+					return err
+				}
+			} else if string(startKey) == "*" {
+				startKey = []byte{}
+			} else {
+				endKey = utils.PrefixNextKey(startKey)
+			}
+
+			ts, err := rc.GetCurrentTS(context.TODO())
+			if err != nil {
+				return err
+			}
+			output := [][]string{{"Key", "Leader", "Follower"}}
+			mismatches, checked := 0, 0
+			progress := false
+			onMismatch := func(k []byte, l, f *client.KV) {
+				mismatches++
+				if mismatches <= verifyMaxShownMismatches {
+					output = append(output, []string{utils.Bytes2ReadableStrLit(k), describeValue(l), describeValue(f)})
+				}
+			}
+			for {
+				leader, err := rc.ScanReplica(context.TODO(), startKey, endKey, ts, false, batchSize)
+				if err != nil {
+					return err
+				}
+				follower, err := rc.ScanReplica(context.TODO(), startKey, endKey, ts, true, batchSize)
+				if err != nil {
+					return err
+				}
+				// a full batch may stop short of the other side's keys, only
+				// compare up to the smaller last key of the full batches
+				var bound []byte
+				if len(leader) == batchSize {
+					bound = leader[len(leader)-1].K
+				}
+				if len(follower) == batchSize {
+					if k := follower[len(follower)-1].K; bound == nil || bytes.Compare(k, bound) < 0 {
+						bound = k
+					}
+				}
+				checked += compareReplicas(leader, follower, bound, onMismatch)
+				if bound == nil {
+					break
+				}
+				startKey = append(append([]byte{}, bound...), 0)
+				fmt.Fprintf(os.Stderr, "\r%d keys checked, %d mismatches", checked, mismatches)
+				progress = true
+			}
+			if progress {
+				fmt.Fprintln(os.Stderr)
+			}
+
+			if mismatches == 0 {
+				utils.Print(fmt.Sprintf("%d keys checked, replicas match", checked))
+				return nil
+			}
+			utils.PrintTable(output)
+			if mismatches > verifyMaxShownMismatches {
+				fmt.Fprintf(os.Stderr, "%d more mismatches not shown\n", mismatches-verifyMaxShownMismatches)
+			}
+			return fmt.Errorf("%d of %d keys differ between leader and follower replicas", mismatches, checked)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package kvcmds
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c4pt0r/tcli/client"
+)
+
+// kvsOf parses "k=v k2=v2" into sorted kv pairs
+func kvsOf(s string) client.KVS {
+	var kvs client.KVS
+	for _, item := range strings.Fields(s) {
+		parts := strings.SplitN(item, "=", 2)
+		kvs = append(kvs, client.KV{K: client.Key(parts[0]), V: client.Value(parts[1])})
+	}
+	return kvs
+}
+
+func TestCompareReplicas(t *testing.T) {
+	tests := []struct {
+		leader, follower string
+		bound            string
+		checked          int
+		mismatches       []string
+	}{
+		{"a=1 b=2", "a=1 b=2", "", 2, nil},
+		{"", "", "", 0, nil},
+		{"a=1 b=2 c=3", "a=1 c=3", "", 3, []string{"b:leader"}},
+		{"a=1 c=3", "a=1 b=2 c=3", "", 3, []string{"b:follower"}},
+		{"a=1 b=2", "a=1 b=x", "", 2, []string{"b:changed"}},
+		{"a=1", "", "", 1, []string{"a:leader"}},
+		{"", "a=1 b=2", "", 2, []string{"a:follower", "b:follower"}},
+		// keys after the bound are left to the next batch
+		{"a=1 b=2 d=4", "a=1 c=3", "c", 3, []string{"b:leader", "c:follower"}},
+		{"a=1 b=2", "a=1 b=2", "a", 1, nil},
+	}
+	for _, tt := range tests {
+		var bound []byte
+		if tt.bound != "" {
+			bound = []byte(tt.bound)
+		}
+		var mismatches []string
+		checked := compareReplicas(kvsOf(tt.leader), kvsOf(tt.follower), bound, func(k []byte, l, f *client.KV) {
+			switch {
+			case f == nil:
+				mismatches = append(mismatches, string(k)+":leader")
+			case l == nil:
+				mismatches = append(mismatches, string(k)+":follower")
+			default:
+				mismatches = append(mismatches, string(k)+":changed")
+			}
+		})
+		if checked != tt.checked || !reflect.DeepEqual(mismatches, tt.mismatches) {
+			t.Errorf("compare [%s] [%s] bound %q: got %d checked, %v, want %d, %v",
+				tt.leader, tt.follower, tt.bound, checked, mismatches, tt.checked, tt.mismatches)
+		}
+	}
+}
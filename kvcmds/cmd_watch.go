@@ -0,0 +1,88 @@
+package kvcmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+type WatchCmd struct{}
+
+var _ tcli.Cmd = WatchCmd{}
+
+func (c WatchCmd) Name() string    { return "watchcdc" }
+func (c WatchCmd) Alias() []string { return []string{"watch"} }
+func (c WatchCmd) Help() string {
+	return `watch inserted/updated/deleted keys with prefix, use "watchcdc --help" for more details`
+}
+
+func (c WatchCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	watchcdc <prefix> <options>
+Options:
+	--interval=<duration>, poll interval, default 1s
+Notes:
+	etcd mode uses the etcd watch API, other modes scan the prefix every interval
+	and diff it with the previous scan, so changes between two polls are merged.
+	Press Ctrl-C to stop.
+Examples:
+	watchcdc "user_"
+	watchcdc "user_" --interval=5s
+`
+	return s
+}
+
+func (c WatchCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			prefix, err := utils.GetStringLit(ic.RawArgs[1])
+			if err != nil {
+				return err
+			}
+			opt := properties.NewProperties()
+			if len(ic.Args) > 1 {
+				err := utils.SetOptByString(ic.Args[1:], opt)
+				if err != nil {
+					return err
+				}
+			}
+			interval := opt.GetParsedDuration(tcli.WatchOptInterval, time.Second)
+			if interval <= 0 {
+				return utils.NewParseError("invalid interval: %v, should be positive", interval)
+			}
+
+			watchCtx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			// Ctrl-C to break
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(sigCh)
+			go func() {
+				select {
+				case <-sigCh:
+					cancel()
+				case <-watchCtx.Done():
+				}
+			}()
+
+			return client.WatchPrefix(watchCtx, client.GetTiKVClient(), prefix, interval, func(ev client.WatchEvent) {
+				utils.Print(fmt.Sprintf("%s %-6s %s => %s", time.Now().Format("15:04:05"), ev.Type, ev.KV.K, ev.KV.V))
+			})
+		})
+	}
+}
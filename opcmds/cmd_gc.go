@@ -0,0 +1,153 @@
+package opcmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+type GCCmd struct{}
+
+var _ tcli.Cmd = GCCmd{}
+
+func (c GCCmd) Name() string    { return "gc" }
+func (c GCCmd) Alias() []string { return []string{"gc"} }
+func (c GCCmd) Help() string {
+	return `show or advance the GC safe point, use "gc --help" for more details`
+}
+
+func (c GCCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	gc show
+	gc advance <tso | "2006-01-02 15:04:05" | duration ago> [--yes]
+Description:
+	show prints the GC safe point, how far it lags behind and the smallest
+	service safe point (BR, CDC...). Automatic GC is run by TiDB's GC worker,
+	PD doesn't know whether it is enabled: a safe point lagging far behind
+	its usual life time means GC isn't running.
+
+	advance resolves the locks older than the new safe point and moves the
+	safe point to it, TiKV then deletes the MVCC versions it makes obsolete.
+	It refuses safe points less than 10 minutes old or past a service safe
+	point, and asks for confirmation unless --yes is given. Txn mode only.
+Examples:
+	gc show
+	gc advance 24h
+	gc advance "2026-10-17 08:00:00"
+	gc advance 449934215332872192 --yes
+`
+	return s
+}
+
+// data younger than this is never collected by gc advance, TiDB refuses a
+// GC life time shorter than 10 minutes for the same reason
+const gcMinLifeTime = 10 * time.Minute
+
+// parseSafePoint accepts a TSO, a local time or a duration before now
+func parseSafePoint(s string, now uint64) (uint64, error) {
+	ts, err := utils.ParseTS(s, now)
+	if err != nil {
+		return 0, utils.NewParseError("invalid safe point %q, should be a TSO, a time or a duration", s)
+	}
+	return ts, nil
+}
+
+func (c GCCmd) show(gc client.GCClient) error {
+	ctx := context.TODO()
+	safePoint, err := gc.GetGCSafePoint(ctx)
+	if err != nil {
+		return err
+	}
+	serviceSafePoint, err := gc.GetMinServiceSafePoint(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := gc.GetCurrentTS(ctx)
+	if err != nil {
+		return err
+	}
+	age := "-"
+	if safePoint > 0 {
+		age = oracle.GetTimeFromTS(now).Sub(oracle.GetTimeFromTS(safePoint)).Round(time.Second).String()
+	}
+	utils.PrintTable([][]string{
+		{"GC", "Value"},
+		{"Safe Point", utils.FormatTS(safePoint)},
+		{"Safe Point Age", age},
+		{"Min Service Safe Point", utils.FormatTS(serviceSafePoint)},
+		{"Current TSO", utils.FormatTS(now)},
+	})
+	return nil
+}
+
+func (c GCCmd) advance(ctx context.Context, gc client.GCClient, arg string) error {
+	now, err := gc.GetCurrentTS(context.TODO())
+	if err != nil {
+		return err
+	}
+	ts, err := parseSafePoint(arg, now)
+	if err != nil {
+		return err
+	}
+	safePoint, err := gc.GetGCSafePoint(context.TODO())
+	if err != nil {
+		return err
+	}
+	serviceSafePoint, err := gc.GetMinServiceSafePoint(context.TODO())
+	if err != nil {
+		return err
+	}
+	switch {
+	case ts <= safePoint:
+		return fmt.Errorf("the safe point is already at %s", utils.FormatTS(safePoint))
+	case oracle.GetTimeFromTS(now).Sub(oracle.GetTimeFromTS(ts)) < gcMinLifeTime:
+		return fmt.Errorf("refusing a safe point less than %s old, running transactions may still read it", gcMinLifeTime)
+	case serviceSafePoint > 0 && ts > serviceSafePoint:
+		return fmt.Errorf("a service safe point holds GC at %s", utils.FormatTS(serviceSafePoint))
+	}
+	if !utils.HasForceYes(ctx) {
+		msg := fmt.Sprintf("Advance the GC safe point from %s to %s? Older MVCC versions will be deleted",
+			utils.FormatTS(safePoint), utils.FormatTS(ts))
+		if utils.AskYesNo(msg, "no") != 1 {
+			return errors.New("cancelled")
+		}
+	}
+	newSafePoint, err := gc.GC(context.TODO(), ts)
+	if err != nil {
+		return err
+	}
+	utils.Print(fmt.Sprintf("GC safe point: %s", utils.FormatTS(newSafePoint)))
+	return nil
+}
+
+func (c GCCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			args, _ := utils.GetArgsAndOptionFlag(ic.Args)
+			if len(args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			gc, ok := client.GetTiKVClient().(client.GCClient)
+			if !ok {
+				return errors.New("gc is only supported in txn mode")
+			}
+			switch {
+			case args[0] == "show":
+				return c.show(gc)
+			case args[0] == "advance" && len(args) == 2:
+				return c.advance(ctx, gc, args[1])
+			}
+			return utils.NewParseError("usage: gc show | gc advance <safe point>")
+		})
+	}
+}
@@ -2,27 +2,173 @@ package opcmds
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
 )
 
-// not implemented yet
-
 type PingCmd struct{}
 
 var _ tcli.Cmd = PingCmd{}
 
-func (c PingCmd) Name() string    { return ".ping" }
-func (c PingCmd) Alias() []string { return []string{".p"} }
+func (c PingCmd) Name() string    { return "ping" }
+func (c PingCmd) Alias() []string { return []string{".ping", ".p"} }
 func (c PingCmd) Help() string {
-	return "ping pd, usage: [.ping|.p] 192.168.1.1:2379"
+	return `measure the round trip time to PD and every store, use "ping --help" for more details`
 }
 
 func (c PingCmd) LongHelp() string {
-	return c.Help()
+	s := c.Help()
+	s += `
+Usage:
+	ping <options>
+Options:
+	--count=<n>, probes per target, default 3
+	--timeout=<duration>, a probe taking longer is lost, default 2s
+Description:
+	Connects to the client URL of every PD member and to the address of
+	every store that is not a tombstone, and times a TSO request to the PD
+	leader, which is what every transaction starts with. Txn mode only.
+	The prompt shows [slow] or [down] when the background keepalive probe
+	(see sys.keepalive_interval) finds the cluster slow or unreachable.
+Examples:
+	ping
+	ping --count=10 --timeout=500ms
+`
+	return s
+}
+
+type pingResult struct {
+	target, addr  string
+	min, max, sum time.Duration
+	ok, lost      int
+	err           error
+}
+
+func (r *pingResult) add(d time.Duration, err error) {
+	if err != nil {
+		r.lost++
+		r.err = err
+		return
+	}
+	if r.ok == 0 || d < r.min {
+		r.min = d
+	}
+	if d > r.max {
+		r.max = d
+	}
+	r.sum += d
+	r.ok++
+}
+
+func (r *pingResult) row() []string {
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.1f ms", float64(d.Microseconds())/1000)
+	}
+	row := []string{r.target, r.addr, "-", "-", "-", fmt.Sprintf("%d/%d", r.lost, r.ok+r.lost), ""}
+	if r.ok > 0 {
+		row[2], row[3], row[4] = ms(r.min), ms(r.sum/time.Duration(r.ok)), ms(r.max)
+	}
+	if r.err != nil {
+		row[6] = r.err.Error()
+	}
+	return row
+}
+
+// pingAddr times TCP connects, which is one round trip
+func pingAddr(addr string, count int, timeout time.Duration) *pingResult {
+	r := &pingResult{addr: addr}
+	for i := 0; i < count; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err == nil {
+			conn.Close()
+		}
+		r.add(time.Since(start), err)
+	}
+	return r
+}
+
+// hostPort strips the scheme of a PD client URL
+func hostPort(clientURL string) string {
+	if u, err := url.Parse(clientURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return clientURL
 }
 
 func (c PingCmd) Handler() func(ctx context.Context) {
 	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			kvc := client.GetTiKVClient()
+			if kvc.GetClientMode() != client.TXN_CLIENT {
+				return errors.New("ping is only supported in txn mode")
+			}
+			opt := properties.NewProperties()
+			if err := utils.SetOptByString(ic.Args, opt); err != nil {
+				return err
+			}
+			count := opt.GetInt(tcli.PingOptCount, 3)
+			if count <= 0 {
+				return utils.NewParseError("count should be positive")
+			}
+			timeout, err := time.ParseDuration(opt.GetString(tcli.PingOptTimeout, "2s"))
+			if err != nil || timeout <= 0 {
+				return utils.NewParseError("invalid timeout, should be a duration like 500ms")
+			}
+
+			var results []*pingResult
+			pdClient := kvc.GetPDClient()
+			tso := &pingResult{target: "pd tso", addr: pdClient.GetLeaderAddr()}
+			for i := 0; i < count; i++ {
+				tctx, cancel := context.WithTimeout(context.Background(), timeout)
+				start := time.Now()
+				_, _, err := pdClient.GetTS(tctx)
+				tso.add(time.Since(start), err)
+				cancel()
+			}
+			results = append(results, tso)
+
+			pds, err := kvc.GetPDs()
+			if err != nil {
+				return err
+			}
+			for _, pd := range pds {
+				if len(pd.ClientURLs) == 0 {
+					continue
+				}
+				r := pingAddr(hostPort(pd.ClientURLs[0]), count, timeout)
+				r.target = "pd " + pd.Name
+				results = append(results, r)
+			}
+			stores, err := kvc.GetStores()
+			if err != nil {
+				return err
+			}
+			for _, store := range stores {
+				if strings.EqualFold(store.State, "Tombstone") {
+					continue
+				}
+				r := pingAddr(store.Addr, count, timeout)
+				r.target = "store " + store.ID
+				results = append(results, r)
+			}
+
+			output := [][]string{{"Target", "Address", "Min", "Avg", "Max", "Lost", "Error"}}
+			for _, r := range results {
+				output = append(output, r.row())
+			}
+			utils.PrintTable(output)
+			return nil
+		})
 	}
 }
@@ -0,0 +1,49 @@
+package opcmds
+
+import (
+	"context"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+)
+
+type SessionCmd struct{}
+
+var _ tcli.Cmd = SessionCmd{}
+
+func (c SessionCmd) Name() string    { return ".session" }
+func (c SessionCmd) Alias() []string { return []string{".session", ".s"} }
+func (c SessionCmd) Help() string {
+	return "show session state: connection, system variables and variables"
+}
+
+func (c SessionCmd) LongHelp() string {
+	return c.Help()
+}
+
+func (c SessionCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			kvClient := client.GetTiKVClient()
+			output := [][]string{
+				{"Session", "Value"},
+				{"Mode", kvClient.GetClientMode().String()},
+				{"Cluster ID", kvClient.GetClusterID()},
+			}
+			if kvClient.GetClientMode() == client.TXN_CLIENT {
+				output = append(output, []string{"PD Leader", kvClient.GetPDClient().GetLeaderAddr()})
+			}
+			if ts := client.PinnedSnapshotTS(); ts != 0 {
+				output = append(output, []string{"Snapshot", utils.FormatTS(ts)})
+			}
+			for _, replica := range client.ReadReplicaNames() {
+				output = append(output, []string{"Read Replica", replica})
+			}
+			utils.PrintTable(output)
+			utils.PrintSysVaribles()
+			utils.PrintGlobalVaribles()
+			return nil
+		})
+	}
+}
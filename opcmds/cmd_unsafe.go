@@ -0,0 +1,120 @@
+package opcmds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+type UnsafeCmd struct{}
+
+var _ tcli.Cmd = UnsafeCmd{}
+
+func (c UnsafeCmd) Name() string    { return "unsafe" }
+func (c UnsafeCmd) Alias() []string { return []string{"unsafe"} }
+func (c UnsafeCmd) Help() string {
+	return `destroy a key range on every TiKV store, use "unsafe --help" for more details`
+}
+
+func (c UnsafeCmd) LongHelp() string {
+	s := c.Help()
+	s += `
+Usage:
+	unsafe destroy range <start key> <end key>
+Description:
+	Deletes all data of [start key, end key) directly from the storage of
+	every TiKV store, bypassing MVCC, transactions and raft: space is freed
+	at once and the data can't be recovered, even from an older snapshot.
+	Running transactions reading the range may fail or see partial data.
+
+	Before anything is deleted, the range and its approximate key count are
+	shown, the range has to be typed back and confirmed once more. There is
+	no way to skip the confirmations. Txn mode only.
+Examples:
+	unsafe destroy range "log_2020" "log_2021"
+	unsafe destroy range h'7480000000000000ff' h'748000000000000100'
+`
+	return s
+}
+
+// confirmDestroy shows what is about to be destroyed, then asks to type the
+// range and to confirm again
+func (c UnsafeCmd) confirmDestroy(startKey, endKey []byte) bool {
+	start, end := utils.Bytes2ReadableStrLit(startKey), utils.Bytes2ReadableStrLit(endKey)
+	regions, keys, size := "unknown", "unknown", "unknown"
+	if rc, ok := client.GetTiKVClient().(client.RegionStatsClient); ok {
+		stats, err := rc.GetRegionStats(context.TODO(), startKey, endKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Can't estimate the key count: %v\n", err)
+		} else {
+			regions = strconv.Itoa(stats.Regions)
+			keys = strconv.FormatInt(stats.ApproximateKeys, 10)
+			size = fmt.Sprintf("%d MiB", stats.ApproximateSize)
+		}
+	}
+	utils.PrintTable([][]string{
+		{"Start Key", "End Key", "Regions", "Approximate Keys", "Approximate Size"},
+		{start, end, regions, keys, size},
+	})
+	fmt.Fprintln(os.Stderr, color.RedString("All data in the range will be deleted from every store, bypassing MVCC. This can't be undone."))
+
+	expected := start + " " + end
+	prompt := promptui.Prompt{
+		Label: fmt.Sprintf("Type %s to confirm", expected),
+	}
+	typed, err := prompt.Run()
+	if err != nil || typed != expected {
+		return false
+	}
+	return utils.AskYesNo(fmt.Sprintf("Destroy [%s, %s)?", start, end), "no") == 1
+}
+
+func (c UnsafeCmd) Handler() func(ctx context.Context) {
+	return func(ctx context.Context) {
+		utils.OutputWithElapse(func() error {
+			ic := utils.ExtractIshellContext(ctx)
+			if len(ic.Args) < 1 {
+				utils.Print(c.LongHelp())
+				return nil
+			}
+			if len(ic.RawArgs) != 5 || ic.RawArgs[1] != "destroy" || ic.RawArgs[2] != "range" {
+				return utils.NewParseError("usage: unsafe destroy range <start key> <end key>")
+			}
+			dc, ok := client.GetTiKVClient().(client.UnsafeDestroyRangeClient)
+			if !ok {
+				return errors.New("unsafe destroy range is only supported in txn mode")
+			}
+			startKey, err := utils.GetStringLit(ic.RawArgs[3])
+			if err != nil {
+				return err
+			}
+			endKey, err := utils.GetStringLit(ic.RawArgs[4])
+			if err != nil {
+				return err
+			}
+			if len(startKey) == 0 || len(endKey) == 0 {
+				return utils.NewParseError("start and end keys can't be empty")
+			}
+			if bytes.Compare(startKey, endKey) >= 0 {
+				return utils.NewParseError("start key should be less than end key")
+			}
+			if !c.confirmDestroy(startKey, endKey) {
+				return errors.New("cancelled")
+			}
+			if err := dc.UnsafeDestroyRange(context.TODO(), startKey, endKey); err != nil {
+				return err
+			}
+			utils.Print("Range destroyed")
+			return nil
+		})
+	}
+}
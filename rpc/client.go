@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// Client is a Go client of `tcli serve -grpc`:
+//
+//	c, err := rpc.Dial("127.0.0.1:8081", "secret", grpc.WithInsecure())
+//	defer c.Close()
+//	err = c.Scan(ctx, &rpc.ScanRequest{Start: []byte("user_"), Prefix: true}, func(kv *rpc.KV) error {
+//		fmt.Println(string(kv.Key))
+//		return nil
+//	})
+type Client struct {
+	conn  *grpc.ClientConn
+	query QueryClient
+}
+
+// tokenCreds sends the bearer token with every call, over TLS or not as
+// the dial options choose
+type tokenCreds struct {
+	token string
+}
+
+func (c tokenCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCreds) RequireTransportSecurity() bool {
+	return false
+}
+
+// Dial connects to addr, token is the bearer token of the server or empty.
+// opts are passed to grpc.Dial, e.g. grpc.WithInsecure() or the transport
+// credentials of a TLS connection.
+func Dial(addr string, token string, opts ...grpc.DialOption) (*Client, error) {
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCreds{token: token}))
+	}
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient uses an existing connection
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn, query: NewQueryClient(conn)}
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Get returns the value of key, and whether the key exists
+func (c *Client) Get(ctx context.Context, key []byte) ([]byte, bool, error) {
+	resp, err := c.query.Get(ctx, &GetRequest{Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	return resp.GetKv().GetValue(), resp.GetFound(), nil
+}
+
+// Scan calls fn for every kv pair of the scan in key order. The server
+// reads ahead only as far as the rows are received, an error returned by
+// fn cancels the scan and is returned.
+func (c *Client) Scan(ctx context.Context, req *ScanRequest, fn func(kv *KV) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.query.Scan(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		kv, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(kv); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) Put(ctx context.Context, key, value []byte) error {
+	_, err := c.query.Put(ctx, &PutRequest{Kv: &KV{Key: key, Value: value}})
+	return err
+}
+
+func (c *Client) Delete(ctx context.Context, key []byte) error {
+	_, err := c.query.Delete(ctx, &DeleteRequest{Key: key})
+	return err
+}
@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+var (
+	protoMessageRe = regexp.MustCompile(`(?s)message\s+(\w+)\s*\{(.*?)\}`)
+	protoFieldRe   = regexp.MustCompile(`^(\w+)\s+(\w+)\s*=\s*(\d+)\s*;$`)
+	protoRPCRe     = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(stream\s+)?(\w+)\s*\)`)
+)
+
+// the Go type and the wire type in the struct tag of proto3 scalars
+var protoScalars = map[string]struct {
+	goType reflect.Type
+	wire   string
+}{
+	"bytes":  {reflect.TypeOf([]byte(nil)), "bytes"},
+	"string": {reflect.TypeOf(""), "bytes"},
+	"bool":   {reflect.TypeOf(false), "varint"},
+	"int32":  {reflect.TypeOf(int32(0)), "varint"},
+	"int64":  {reflect.TypeOf(int64(0)), "varint"},
+	"uint32": {reflect.TypeOf(uint32(0)), "varint"},
+	"uint64": {reflect.TypeOf(uint64(0)), "varint"},
+}
+
+// goFieldName is the Go name protoc-gen-go gives a field, key_only: KeyOnly
+func goFieldName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, p := range parts {
+		parts[i] = strings.Title(p)
+	}
+	return strings.Join(parts, "")
+}
+
+type protoField struct {
+	typ, name string
+	num       int
+}
+
+func readQueryProto(t *testing.T) (map[string][]protoField, []string) {
+	buf, err := ioutil.ReadFile("query.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drop the comments
+	var lines []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		lines = append(lines, line)
+	}
+	src := strings.Join(lines, "\n")
+
+	messages := make(map[string][]protoField)
+	for _, m := range protoMessageRe.FindAllStringSubmatch(src, -1) {
+		fields := []protoField{}
+		for _, line := range strings.Split(m[2], "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			f := protoFieldRe.FindStringSubmatch(line)
+			if f == nil {
+				t.Fatalf("message %s: unsupported field %q", m[1], line)
+			}
+			num, _ := strconv.Atoi(f[3])
+			fields = append(fields, protoField{typ: f[1], name: f[2], num: num})
+		}
+		messages[m[1]] = fields
+	}
+	return messages, protoRPCRe.FindAllString(src, -1)
+}
+
+// query.go is written by hand, check it against query.proto so they can't
+// drift apart
+func TestQueryMessagesMatchProto(t *testing.T) {
+	messages, _ := readQueryProto(t)
+	if len(messages) == 0 {
+		t.Fatal("no message found in query.proto")
+	}
+	for name, fields := range messages {
+		typ := proto.MessageType("tcli.rpc." + name)
+		if typ == nil {
+			t.Errorf("message %s isn't registered", name)
+			continue
+		}
+		st := typ.Elem()
+		tagged := 0
+		for i := 0; i < st.NumField(); i++ {
+			if _, ok := st.Field(i).Tag.Lookup("protobuf"); ok {
+				tagged++
+			}
+		}
+		if tagged != len(fields) {
+			t.Errorf("%s has %d fields, query.proto has %d", name, tagged, len(fields))
+		}
+		for _, f := range fields {
+			goName := goFieldName(f.name)
+			sf, ok := st.FieldByName(goName)
+			if !ok {
+				t.Errorf("%s.%s is missing", name, goName)
+				continue
+			}
+			var wantType reflect.Type
+			wantWire := "bytes"
+			if scalar, ok := protoScalars[f.typ]; ok {
+				wantType, wantWire = scalar.goType, scalar.wire
+			} else if mt := proto.MessageType("tcli.rpc." + f.typ); mt != nil {
+				wantType = mt
+			} else {
+				t.Errorf("%s.%s: unknown type %s", name, f.name, f.typ)
+				continue
+			}
+			if sf.Type != wantType {
+				t.Errorf("%s.%s: Go type %v, want %v for %s", name, goName, sf.Type, wantType, f.typ)
+			}
+			tag := strings.Split(sf.Tag.Get("protobuf"), ",")
+			if len(tag) < 4 || tag[0] != wantWire || tag[1] != strconv.Itoa(f.num) || tag[3] != "name="+f.name {
+				t.Errorf("%s.%s: tag %q, want %s,%d,opt,name=%s", name, goName, sf.Tag.Get("protobuf"), wantWire, f.num, f.name)
+			}
+		}
+	}
+}
+
+func TestQueryServiceMatchesProto(t *testing.T) {
+	_, rpcs := readQueryProto(t)
+	server := reflect.TypeOf((*QueryServer)(nil)).Elem()
+	if len(rpcs) != server.NumMethod() {
+		t.Errorf("QueryServer has %d methods, query.proto has %d rpcs", server.NumMethod(), len(rpcs))
+	}
+	for _, rpc := range rpcs {
+		m := protoRPCRe.FindStringSubmatch(rpc)
+		name, in, stream, out := m[1], "*rpc."+m[2], m[3] != "", "*rpc."+m[4]
+		method, ok := server.MethodByName(name)
+		if !ok {
+			t.Errorf("QueryServer.%s is missing", name)
+			continue
+		}
+		mt := method.Type
+		if !stream {
+			if mt.NumIn() != 2 || mt.In(1).String() != in || mt.NumOut() != 2 || mt.Out(0).String() != out {
+				t.Errorf("QueryServer.%s is %v, want (context.Context, %s) (%s, error)", name, mt, in, out)
+			}
+			continue
+		}
+		if mt.NumIn() != 2 || mt.In(0).String() != in {
+			t.Errorf("QueryServer.%s is %v, want (%s, Query_%sServer) error", name, mt, in, name)
+			continue
+		}
+		send, ok := mt.In(1).MethodByName("Send")
+		if !ok || send.Type.NumIn() != 1 || send.Type.In(0).String() != out {
+			t.Errorf("Query_%sServer should send %s", name, out)
+		}
+		found := false
+		for _, sd := range queryServiceDesc.Streams {
+			found = found || (sd.StreamName == name && sd.ServerStreams)
+		}
+		if !found {
+			t.Errorf("%s isn't a server stream of the service", name)
+		}
+	}
+}
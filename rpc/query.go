@@ -0,0 +1,165 @@
+// Package rpc is the gRPC interface of `tcli serve -grpc`, defined in
+// query.proto, and its Go client.
+package rpc
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+// The messages of query.proto. They are written like protoc-gen-go's
+// output and marshaled by github.com/golang/protobuf through their struct
+// tags, so building needs no protobuf toolchain. proto_test.go checks their
+// fields and the service against query.proto.
+
+type KV struct {
+	Key   []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *KV) Reset()         { *m = KV{} }
+func (m *KV) String() string { return proto.CompactTextString(m) }
+func (*KV) ProtoMessage()    {}
+
+func (m *KV) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *KV) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type GetRequest struct {
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+
+func (m *GetRequest) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type GetResponse struct {
+	Found bool `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Kv    *KV  `protobuf:"bytes,2,opt,name=kv,proto3" json:"kv,omitempty"`
+}
+
+func (m *GetResponse) Reset()         { *m = GetResponse{} }
+func (m *GetResponse) String() string { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()    {}
+
+func (m *GetResponse) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
+func (m *GetResponse) GetKv() *KV {
+	if m != nil {
+		return m.Kv
+	}
+	return nil
+}
+
+type ScanRequest struct {
+	Start   []byte `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Prefix  bool   `protobuf:"varint,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	KeyOnly bool   `protobuf:"varint,3,opt,name=key_only,json=keyOnly,proto3" json:"key_only,omitempty"`
+	Limit   int64  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *ScanRequest) Reset()         { *m = ScanRequest{} }
+func (m *ScanRequest) String() string { return proto.CompactTextString(m) }
+func (*ScanRequest) ProtoMessage()    {}
+
+func (m *ScanRequest) GetStart() []byte {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *ScanRequest) GetPrefix() bool {
+	if m != nil {
+		return m.Prefix
+	}
+	return false
+}
+
+func (m *ScanRequest) GetKeyOnly() bool {
+	if m != nil {
+		return m.KeyOnly
+	}
+	return false
+}
+
+func (m *ScanRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type PutRequest struct {
+	Kv *KV `protobuf:"bytes,1,opt,name=kv,proto3" json:"kv,omitempty"`
+}
+
+func (m *PutRequest) Reset()         { *m = PutRequest{} }
+func (m *PutRequest) String() string { return proto.CompactTextString(m) }
+func (*PutRequest) ProtoMessage()    {}
+
+func (m *PutRequest) GetKv() *KV {
+	if m != nil {
+		return m.Kv
+	}
+	return nil
+}
+
+type PutResponse struct{}
+
+func (m *PutResponse) Reset()         { *m = PutResponse{} }
+func (m *PutResponse) String() string { return proto.CompactTextString(m) }
+func (*PutResponse) ProtoMessage()    {}
+
+type DeleteRequest struct {
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+
+func (m *DeleteRequest) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type DeleteResponse struct{}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*KV)(nil), "tcli.rpc.KV")
+	proto.RegisterType((*GetRequest)(nil), "tcli.rpc.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "tcli.rpc.GetResponse")
+	proto.RegisterType((*ScanRequest)(nil), "tcli.rpc.ScanRequest")
+	proto.RegisterType((*PutRequest)(nil), "tcli.rpc.PutRequest")
+	proto.RegisterType((*PutResponse)(nil), "tcli.rpc.PutResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "tcli.rpc.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "tcli.rpc.DeleteResponse")
+}
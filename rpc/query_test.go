@@ -0,0 +1,41 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// the hand-written messages must encode as query.proto, so clients
+// generated from it in other languages can talk to the server
+func TestQueryMessagesWireFormat(t *testing.T) {
+	tests := []struct {
+		msg  proto.Message
+		want string
+	}{
+		// start = 1 (bytes), prefix = 2, key_only = 3, limit = 4 (varints)
+		{&ScanRequest{Start: []byte("a"), Prefix: true, KeyOnly: true, Limit: 5}, "0a0161" + "1001" + "1801" + "2005"},
+		// found = 1, kv = 2 (message of key = 1, value = 2)
+		{&GetResponse{Found: true, Kv: &KV{Key: []byte("k"), Value: []byte("v")}}, "0801" + "1206" + "0a016b" + "120176"},
+		{&GetResponse{}, ""},
+		{&PutRequest{Kv: &KV{Key: []byte("k")}}, "0a03" + "0a016b"},
+		{&DeleteRequest{Key: []byte("k")}, "0a016b"},
+		{&PutResponse{}, ""},
+	}
+	for _, tt := range tests {
+		b, err := proto.Marshal(tt.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(b); got != tt.want {
+			t.Errorf("%T: got %s, want %s", tt.msg, got, tt.want)
+		}
+		// and decode back to the same message
+		out := proto.Clone(tt.msg)
+		out.Reset()
+		if err := proto.Unmarshal(b, out); err != nil || !proto.Equal(out, tt.msg) {
+			t.Errorf("%T: decoded %v, %v", tt.msg, out, err)
+		}
+	}
+}
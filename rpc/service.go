@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// The Query service of query.proto, in the shape of protoc-gen-go's grpc
+// plugin output.
+
+const serviceName = "tcli.rpc.Query"
+
+// QueryServer is implemented by the server of the Query service
+type QueryServer interface {
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Scan(*ScanRequest, Query_ScanServer) error
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+}
+
+// Query_ScanServer sends the rows of a scan, Send blocks while the client
+// is not receiving them
+type Query_ScanServer interface {
+	Send(*KV) error
+	grpc.ServerStream
+}
+
+type queryScanServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryScanServer) Send(m *KV) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func RegisterQueryServer(s *grpc.Server, srv QueryServer) {
+	s.RegisterService(&queryServiceDesc, srv)
+}
+
+func queryGetHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func queryPutHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/Put"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func queryDeleteHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/Delete"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func queryScanHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServer).Scan(m, &queryScanServer{stream})
+}
+
+var queryServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*QueryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Get", Handler: queryGetHandler},
+		{MethodName: "Put", Handler: queryPutHandler},
+		{MethodName: "Delete", Handler: queryDeleteHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Scan", Handler: queryScanHandler, ServerStreams: true},
+	},
+	Metadata: "query.proto",
+}
+
+// QueryClient is the client stub of the Query service
+type QueryClient interface {
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (Query_ScanClient, error)
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+}
+
+// Query_ScanClient receives the rows of a scan, Recv returns io.EOF at the
+// end
+type Query_ScanClient interface {
+	Recv() (*KV, error)
+	grpc.ClientStream
+}
+
+type queryClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewQueryClient(cc *grpc.ClientConn) QueryClient {
+	return &queryClient{cc}
+}
+
+func (c *queryClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/Get", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	out := new(PutResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/Put", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/Delete", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (Query_ScanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &queryServiceDesc.Streams[0], "/"+serviceName+"/Scan", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queryScanClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type queryScanClient struct {
+	grpc.ClientStream
+}
+
+func (x *queryScanClient) Recv() (*KV, error) {
+	m := new(KV)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService serves rpc.QueryServer with the tokens, process list and
+// metrics of the HTTP API
+type grpcService struct {
+	s *Server
+}
+
+var _ rpc.QueryServer = grpcService{}
+
+// authorize checks the bearer token of the "authorization" metadata
+func (g grpcService) authorize(ctx context.Context, write bool) error {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			authorization = v[0]
+		}
+	}
+	ok, canWrite := g.s.access(authorization)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if write && !canWrite {
+		return status.Error(codes.PermissionDenied, "read-only access")
+	}
+	return nil
+}
+
+func (g grpcService) Get(ctx context.Context, req *rpc.GetRequest) (*rpc.GetResponse, error) {
+	if err := g.authorize(ctx, false); err != nil {
+		return nil, err
+	}
+	kv, found, err := getKey(ctx, req.GetKey())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !found {
+		return &rpc.GetResponse{}, nil
+	}
+	return &rpc.GetResponse{Found: true, Kv: &rpc.KV{Key: kv.K, Value: kv.V}}, nil
+}
+
+func (g grpcService) Scan(req *rpc.ScanRequest, stream rpc.Query_ScanServer) error {
+	if err := g.authorize(stream.Context(), false); err != nil {
+		return err
+	}
+	ctx, proc := g.s.procs.register(stream.Context(), "grpc scan", req.String())
+	defer g.s.procs.unregister(proc)
+
+	startKey := req.GetStart()
+	if len(startKey) == 0 {
+		startKey = []byte("\x00")
+	}
+	var prefix []byte
+	if req.GetPrefix() {
+		prefix = req.GetStart()
+	}
+	err := scanBatches(ctx, startKey, prefix, req.GetKeyOnly(), int(req.GetLimit()), func(kvs client.KVS) error {
+		atomic.AddInt64(&proc.Rows, int64(len(kvs)))
+		for _, kv := range kvs {
+			// blocks while the client is behind, so the scan waits for it
+			if err := stream.Send(&rpc.KV{Key: kv.K, Value: kv.V}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		// cancelled by the client or killed
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (g grpcService) Put(ctx context.Context, req *rpc.PutRequest) (*rpc.PutResponse, error) {
+	if err := g.authorize(ctx, true); err != nil {
+		return nil, err
+	}
+	kv := req.GetKv()
+	if len(kv.GetKey()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	if err := client.GetTiKVClient().Put(ctx, client.KV{K: kv.GetKey(), V: kv.GetValue()}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &rpc.PutResponse{}, nil
+}
+
+func (g grpcService) Delete(ctx context.Context, req *rpc.DeleteRequest) (*rpc.DeleteResponse, error) {
+	if err := g.authorize(ctx, true); err != nil {
+		return nil, err
+	}
+	if len(req.GetKey()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	if err := client.GetTiKVClient().Delete(ctx, req.GetKey()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &rpc.DeleteResponse{}, nil
+}
+
+// NewGRPCServer returns a gRPC server of the Query service, sharing the
+// tokens and process list of s
+func (s *Server) NewGRPCServer() *grpc.Server {
+	gs := grpc.NewServer()
+	rpc.RegisterQueryServer(gs, grpcService{s: s})
+	return gs
+}
+
+func (s *Server) ListenAndServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.I("tcli grpc server listening on", addr)
+	return s.NewGRPCServer().Serve(lis)
+}
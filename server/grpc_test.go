@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves s over an in-memory connection and returns a client
+// using token
+func dialGRPC(t *testing.T, s *Server, token string) *rpc.Client {
+	lis := bufconn.Listen(1 << 20)
+	gs := s.NewGRPCServer()
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+	c, err := rpc.Dial("bufnet", token, grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestGRPCQuery(t *testing.T) {
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	var kvs []client.KV
+	for i := 0; i < 2500; i++ {
+		kvs = append(kvs, client.KV{K: client.Key(fmt.Sprintf("row_%05d", i)), V: client.Value(fmt.Sprintf("v%d", i))})
+	}
+	kvs = append(kvs, client.KV{K: client.Key("rowz"), V: client.Value("v")})
+	if err := client.GetTiKVClient().BatchPut(context.TODO(), kvs); err != nil {
+		t.Fatal(err)
+	}
+	c := dialGRPC(t, NewServer(Config{Token: "secret"}), "secret")
+	ctx := context.TODO()
+
+	scans := []struct {
+		req  rpc.ScanRequest
+		want int
+	}{
+		{rpc.ScanRequest{Start: []byte("row_"), Prefix: true}, 2500},
+		{rpc.ScanRequest{Start: []byte("row_"), Prefix: true, Limit: 1200}, 1200},
+		{rpc.ScanRequest{Start: []byte("row_01"), Prefix: true, KeyOnly: true}, 1000},
+		{rpc.ScanRequest{Start: []byte("row_"), Limit: 3000}, 2501},
+		{rpc.ScanRequest{Start: []byte("nothing_"), Prefix: true}, 0},
+	}
+	for _, tt := range scans {
+		got := 0
+		var last []byte
+		err := c.Scan(ctx, &tt.req, func(kv *rpc.KV) error {
+			if last != nil && string(kv.Key) <= string(last) {
+				t.Errorf("%v: %s after %s", tt.req.String(), kv.Key, last)
+			}
+			if tt.req.KeyOnly != (len(kv.Value) == 0) {
+				t.Errorf("%v: unexpected value %q", tt.req.String(), kv.Value)
+			}
+			last = kv.Key
+			got++
+			return nil
+		})
+		if err != nil || got != tt.want {
+			t.Errorf("%v: got %d rows, %v, want %d", tt.req.String(), got, err, tt.want)
+		}
+	}
+
+	// stopping early cancels the scan
+	stop := fmt.Errorf("stop")
+	got := 0
+	err := c.Scan(ctx, &rpc.ScanRequest{Start: []byte("row_"), Prefix: true}, func(kv *rpc.KV) error {
+		if got++; got == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || got != 10 {
+		t.Errorf("stopped scan: got %d rows, %v", got, err)
+	}
+
+	if err := c.Put(ctx, []byte("grpc_k"), []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	v, found, err := c.Get(ctx, []byte("grpc_k"))
+	if err != nil || !found || string(v) != "v1" {
+		t.Errorf("get: got %q, %v, %v", v, found, err)
+	}
+	if err := c.Delete(ctx, []byte("grpc_k")); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := c.Get(ctx, []byte("grpc_k")); err != nil || found {
+		t.Errorf("get deleted key: got %v, %v", found, err)
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(Config{Token: "secret", ReadOnlyToken: "readonly"})
+	ctx := context.TODO()
+	tests := []struct {
+		token    string
+		getCode  codes.Code
+		putCode  codes.Code
+		scanCode codes.Code
+	}{
+		{"secret", codes.OK, codes.OK, codes.OK},
+		{"readonly", codes.OK, codes.PermissionDenied, codes.OK},
+		{"wrong", codes.Unauthenticated, codes.Unauthenticated, codes.Unauthenticated},
+		{"", codes.Unauthenticated, codes.Unauthenticated, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		c := dialGRPC(t, s, tt.token)
+		_, _, err := c.Get(ctx, []byte("k"))
+		if code := status.Code(err); code != tt.getCode {
+			t.Errorf("token %q get: got %v, want %v", tt.token, code, tt.getCode)
+		}
+		err = c.Put(ctx, []byte("k"), []byte("v"))
+		if code := status.Code(err); code != tt.putCode {
+			t.Errorf("token %q put: got %v, want %v", tt.token, code, tt.putCode)
+		}
+		err = c.Scan(ctx, &rpc.ScanRequest{}, func(*rpc.KV) error { return nil })
+		if code := status.Code(err); code != tt.scanCode {
+			t.Errorf("token %q scan: got %v, want %v", tt.token, code, tt.scanCode)
+		}
+	}
+}
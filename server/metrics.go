@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	tikvmetrics "github.com/tikv/client-go/v2/metrics"
+)
+
+var (
+	requestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tcli",
+			Subsystem: "server",
+			Name:      "requests_total",
+			Help:      "Counter of HTTP API requests.",
+		}, []string{"handler", "code", "method"})
+
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tcli",
+			Subsystem: "server",
+			Name:      "request_duration_seconds",
+			Help:      "Bucketed histogram of HTTP API request latency.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20), // 0.5ms ~ 524s
+		}, []string{"handler", "code", "method"})
+
+	scannedKeysCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tcli",
+			Subsystem: "server",
+			Name:      "scanned_keys_total",
+			Help:      "Counter of kv pairs returned by scans.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(requestCounter)
+	prometheus.MustRegister(requestDuration)
+	prometheus.MustRegister(scannedKeysCounter)
+	// TiKV client RPC, region cache and backoff stats
+	tikvmetrics.RegisterMetrics()
+}
+
+// instrument records count and latency of a handler
+func instrument(name string, h http.HandlerFunc) http.HandlerFunc {
+	labels := prometheus.Labels{"handler": name}
+	return promhttp.InstrumentHandlerCounter(
+		requestCounter.MustCurryWith(labels),
+		promhttp.InstrumentHandlerDuration(requestDuration.MustCurryWith(labels), h),
+	)
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// process is a running API request
+type process struct {
+	// number of kv pairs scanned so far, updated atomically
+	Rows    int64
+	ID      uint64
+	Handler string
+	Query   string
+	Start   time.Time
+
+	cancel context.CancelFunc
+}
+
+type processInfo struct {
+	ID        uint64 `json:"id"`
+	Handler   string `json:"handler"`
+	Query     string `json:"query"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Rows      int64  `json:"rows"`
+}
+
+type processList struct {
+	mu     sync.Mutex
+	nextID uint64
+	procs  map[uint64]*process
+}
+
+func newProcessList() *processList {
+	return &processList{procs: make(map[uint64]*process)}
+}
+
+// register adds a process, the returned context is cancelled by kill
+func (pl *processList) register(ctx context.Context, handler, query string) (context.Context, *process) {
+	ctx, cancel := context.WithCancel(ctx)
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.nextID++
+	p := &process{
+		ID:      pl.nextID,
+		Handler: handler,
+		Query:   query,
+		Start:   time.Now(),
+		cancel:  cancel,
+	}
+	pl.procs[p.ID] = p
+	return ctx, p
+}
+
+func (pl *processList) unregister(p *process) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	p.cancel()
+	delete(pl.procs, p.ID)
+}
+
+func (pl *processList) kill(id uint64) bool {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	p, ok := pl.procs[id]
+	if ok {
+		p.cancel()
+	}
+	return ok
+}
+
+func (pl *processList) list() []processInfo {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	ret := []processInfo{}
+	for _, p := range pl.procs {
+		ret = append(ret, processInfo{
+			ID:        p.ID,
+			Handler:   p.Handler,
+			Query:     p.Query,
+			ElapsedMs: int64(time.Since(p.Start) / time.Millisecond),
+			Rows:      atomic.LoadInt64(&p.Rows),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/magiconair/properties"
+)
+
+// Job is a periodic scan whose result is written to an output, e.g.
+//
+//	{"name": "users", "every": "1h", "kind": "count", "prefix": "user_", "output": "file:/tmp/users.ndjson"}
+//
+// Outputs:
+//
+//	file:<path>   append one JSON line per run
+//	key:<prefix>  put the JSON result at <prefix><unix timestamp>
+//	http(s)://... POST the JSON result to a webhook
+type Job struct {
+	Name   string `json:"name"`
+	Every  string `json:"every"`
+	Kind   string `json:"kind"` // count | scan
+	Prefix string `json:"prefix"`
+	Limit  int    `json:"limit"`
+	Output string `json:"output"`
+}
+
+type jobResult struct {
+	Job   string   `json:"job"`
+	Time  int64    `json:"time"`
+	Count int      `json:"count"`
+	Kvs   []kvItem `json:"kvs,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// webhookClient posts job results, a hung webhook delays the job's next
+// run by the timeout at most
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// LoadJobs reads a JSON array of jobs
+func LoadJobs(fname string) ([]Job, error) {
+	buf, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	if err := json.Unmarshal(buf, &jobs); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		every, err := time.ParseDuration(job.Every)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: invalid interval: %v", job.Name, err)
+		}
+		if every <= 0 {
+			return nil, fmt.Errorf("job %s: interval should be positive, got %s", job.Name, job.Every)
+		}
+		if job.Kind != "count" && job.Kind != "scan" {
+			return nil, fmt.Errorf("job %s: kind should be count or scan", job.Name)
+		}
+		if !strings.HasPrefix(job.Output, "file:") && !strings.HasPrefix(job.Output, "key:") &&
+			!strings.HasPrefix(job.Output, "http://") && !strings.HasPrefix(job.Output, "https://") {
+			return nil, fmt.Errorf("job %s: unknown output: %s", job.Name, job.Output)
+		}
+	}
+	return jobs, nil
+}
+
+// RunJobs starts one goroutine per job, they stop when ctx is done
+func RunJobs(ctx context.Context, jobs []Job) {
+	for _, job := range jobs {
+		go runJob(ctx, job)
+	}
+}
+
+func runJob(ctx context.Context, job Job) {
+	every, _ := time.ParseDuration(job.Every)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res := job.run(ctx)
+			if err := job.write(ctx, res); err != nil {
+				log.E("job", job.Name, "write result failed:", err)
+			}
+		}
+	}
+}
+
+func (job Job) run(ctx context.Context) jobResult {
+	res := jobResult{Job: job.Name, Time: time.Now().Unix()}
+	prefix := []byte("\x00")
+	if job.Prefix != "" {
+		var err error
+		if prefix, err = utils.GetStringLit(job.Prefix); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+	}
+	opt := properties.NewProperties()
+	opt.Set(tcli.ScanOptStrictPrefix, strconv.FormatBool(len(job.Prefix) > 0))
+	if job.Kind == "count" {
+		opt.Set(tcli.ScanOptCountOnly, "true")
+		opt.Set(tcli.ScanOptKeyOnly, "true")
+	} else {
+		limit := job.Limit
+		if limit <= 0 {
+			limit = 100
+		}
+		opt.Set(tcli.ScanOptLimit, strconv.Itoa(limit))
+	}
+	kvs, cnt, err := client.GetTiKVClient().Scan(utils.ContextWithProp(ctx, opt), prefix)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Count = cnt
+	if job.Kind == "scan" {
+		for _, kv := range kvs {
+			res.Kvs = append(res.Kvs, kvItem{Key: string(kv.K), Value: string(kv.V)})
+		}
+	}
+	return res
+}
+
+func (job Job) write(ctx context.Context, res jobResult) error {
+	buf, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(job.Output, "file:"):
+		fp, err := os.OpenFile(strings.TrimPrefix(job.Output, "file:"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		_, err = fp.Write(append(buf, '\n'))
+		return err
+	case strings.HasPrefix(job.Output, "key:"):
+		k := fmt.Sprintf("%s%d", strings.TrimPrefix(job.Output, "key:"), res.Time)
+		return client.GetTiKVClient().Put(ctx, client.KV{K: []byte(k), V: buf})
+	case strings.HasPrefix(job.Output, "http://"), strings.HasPrefix(job.Output, "https://"):
+		req, err := http.NewRequest(http.MethodPost, job.Output, bytes.NewReader(buf))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := webhookClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("webhook returned %s", resp.Status)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output: %s", job.Output)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadJobs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tcli-jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`[{"name": "a", "every": "1h", "kind": "count", "output": "file:/tmp/a"}]`, false},
+		{`[{"name": "a", "every": "1s", "kind": "scan", "output": "https://example.com/hook"}]`, false},
+		{`[{"name": "a", "every": "0s", "kind": "count", "output": "file:/tmp/a"}]`, true},
+		{`[{"name": "a", "every": "-1m", "kind": "count", "output": "file:/tmp/a"}]`, true},
+		{`[{"name": "a", "every": "often", "kind": "count", "output": "file:/tmp/a"}]`, true},
+		{`[{"name": "a", "every": "1h", "kind": "sum", "output": "file:/tmp/a"}]`, true},
+		{`[{"name": "a", "every": "1h", "kind": "count", "output": "ftp://example.com"}]`, true},
+	}
+	fname := filepath.Join(dir, "jobs.json")
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(fname, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadJobs(fname)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.json, err, tt.wantErr)
+		}
+	}
+}
+
+func TestJobWebhookTimeout(t *testing.T) {
+	done := make(chan struct{})
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer hook.Close()
+	// unblock the handler before Close waits for it
+	defer close(done)
+
+	saved := webhookClient.Timeout
+	webhookClient.Timeout = 100 * time.Millisecond
+	defer func() { webhookClient.Timeout = saved }()
+
+	job := Job{Name: "hook", Output: hook.URL}
+	errCh := make(chan error, 1)
+	go func() { errCh <- job.write(context.TODO(), jobResult{Job: job.Name}) }()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Error("expected a timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook post didn't time out")
+	}
+}
@@ -0,0 +1,318 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/c4pt0r/log"
+	"github.com/c4pt0r/tcli/client"
+	"github.com/c4pt0r/tcli/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ScanBatchSize is how many kv pairs are fetched from the client per round
+var ScanBatchSize = 1000
+
+type Config struct {
+	// Token grants full access, no auth if both tokens are empty
+	Token string
+	// ReadOnlyToken grants read access only
+	ReadOnlyToken string
+	// ReadOnly rejects all writes regardless of the token
+	ReadOnly bool
+}
+
+// Server exposes the global kv client over HTTP
+type Server struct {
+	cfg   Config
+	mux   *http.ServeMux
+	procs *processList
+}
+
+type kvItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type errorResp struct {
+	Error string `json:"error"`
+}
+
+func NewServer(cfg Config) *Server {
+	s := &Server{
+		cfg:   cfg,
+		mux:   http.NewServeMux(),
+		procs: newProcessList(),
+	}
+	s.mux.HandleFunc("/api/v1/get", instrument("get", s.auth(false, s.handleGet)))
+	s.mux.HandleFunc("/api/v1/scan", instrument("scan", s.auth(false, s.handleScan)))
+	s.mux.HandleFunc("/api/v1/put", instrument("put", s.auth(true, s.handlePut)))
+	s.mux.HandleFunc("/api/v1/delete", instrument("delete", s.auth(true, s.handleDelete)))
+	s.mux.HandleFunc("/api/v1/watch", instrument("watch", s.auth(false, s.handleWatch)))
+	s.mux.HandleFunc("/api/v1/processlist", instrument("processlist", s.auth(false, s.handleProcessList)))
+	s.mux.HandleFunc("/api/v1/kill", instrument("kill", s.auth(true, s.handleKill)))
+	s.mux.Handle("/metrics", promhttp.Handler())
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	log.I("tcli http server listening on", addr)
+	return http.ListenAndServe(addr, s)
+}
+
+// access checks the value of an Authorization header, it tells if the
+// token is valid and if it grants writes
+func (s *Server) access(authorization string) (ok bool, canWrite bool) {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	canWrite = !s.cfg.ReadOnly
+	switch {
+	case s.cfg.Token == "" && s.cfg.ReadOnlyToken == "":
+	case s.cfg.Token != "" && tokenEqual(token, s.cfg.Token):
+	case s.cfg.ReadOnlyToken != "" && tokenEqual(token, s.cfg.ReadOnlyToken):
+		canWrite = false
+	default:
+		return false, false
+	}
+	return true, canWrite
+}
+
+// auth checks the bearer token, write handlers need the full access token
+func (s *Server) auth(write bool, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok, canWrite := s.access(r.Header.Get("Authorization"))
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if write && !canWrite {
+			writeError(w, http.StatusForbidden, "read-only access")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// tokenEqual compares in constant time, so the token can't be guessed
+// from response times
+func tokenEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResp{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// keyParam reads a string literal (e.g. h'6b31') from the query string
+func keyParam(r *http.Request, name string) ([]byte, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, false
+	}
+	k, err := utils.GetStringLit(raw)
+	if err != nil {
+		return nil, false
+	}
+	return k, true
+}
+
+// errorStatus is 503 if the store can't be reached, 500 for other errors
+func errorStatus(err error) int {
+	if utils.IsUnreachableErr(err) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// getKey reads k, found is false if it doesn't exist. BatchGet tells a
+// missing key from an error in every mode, Get doesn't.
+func getKey(ctx context.Context, k []byte) (kv client.KV, found bool, err error) {
+	kvs, err := client.GetTiKVClient().BatchGet(ctx, []client.Key{k})
+	if err != nil || len(kvs) == 0 {
+		return client.KV{}, false, err
+	}
+	return kvs[0], true, nil
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	k, ok := keyParam(r, "key")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "missing key")
+		return
+	}
+	kv, found, err := getKey(r.Context(), k)
+	switch {
+	case err != nil:
+		writeError(w, errorStatus(err), err.Error())
+		return
+	case !found:
+		writeError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	writeJSON(w, kvItem{Key: string(kv.K), Value: string(kv.V)})
+}
+
+// scanBatches scans the connected store in batches of ScanBatchSize, see
+// client.ScanBatches, and counts the keys scanned
+func scanBatches(ctx context.Context, startKey, prefix []byte, keyOnly bool, limit int, fn func(kvs client.KVS) error) error {
+	return client.ScanBatches(ctx, client.GetTiKVClient(), startKey, prefix, keyOnly, ScanBatchSize, limit, func(kvs client.KVS) error {
+		scannedKeysCounter.Add(float64(len(kvs)))
+		return fn(kvs)
+	})
+}
+
+// handleScan streams kv pairs as NDJSON, one object per line
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	startKey, ok := keyParam(r, "start")
+	if !ok {
+		startKey = []byte("\x00")
+	}
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	var prefix []byte
+	if q.Get("prefix") == "true" {
+		prefix = startKey
+	}
+
+	ctx, proc := s.procs.register(r.Context(), "scan", r.URL.RawQuery)
+	defer s.procs.unregister(proc)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	err = scanBatches(ctx, startKey, prefix, q.Get("key-only") == "true", limit, func(kvs client.KVS) error {
+		atomic.AddInt64(&proc.Rows, int64(len(kvs)))
+		for _, kv := range kvs {
+			if err := enc.Encode(kvItem{Key: string(kv.K), Value: string(kv.V)}); err != nil {
+				return err
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	// nothing can be sent once the client went away
+	if err != nil && r.Context().Err() == nil {
+		enc.Encode(errorResp{Error: err.Error()})
+	}
+}
+
+type watchEvent struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// handleWatch streams changes under a prefix as NDJSON until the client
+// disconnects or the request is killed
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	prefix, ok := keyParam(r, "prefix")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "missing prefix")
+		return
+	}
+	interval, err := time.ParseDuration(r.URL.Query().Get("interval"))
+	if err != nil || interval <= 0 {
+		interval = time.Second
+	}
+	ctx, proc := s.procs.register(r.Context(), "watch", r.URL.RawQuery)
+	defer s.procs.unregister(proc)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	err = client.WatchPrefix(ctx, client.GetTiKVClient(), prefix, interval, func(ev client.WatchEvent) {
+		atomic.AddInt64(&proc.Rows, 1)
+		enc.Encode(watchEvent{Type: ev.Type, Key: string(ev.KV.K), Value: string(ev.KV.V)})
+		if flusher != nil {
+			flusher.Flush()
+		}
+	})
+	if err != nil {
+		enc.Encode(errorResp{Error: err.Error()})
+	}
+}
+
+func (s *Server) handlePut(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	var item kvItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// cancelled when the client goes away or the write is killed
+	ctx, proc := s.procs.register(r.Context(), "put", "key="+item.Key)
+	defer s.procs.unregister(proc)
+	err := client.GetTiKVClient().Put(ctx, client.KV{K: []byte(item.Key), V: []byte(item.Value)})
+	if err != nil {
+		writeError(w, errorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, item)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "use POST or DELETE")
+		return
+	}
+	k, ok := keyParam(r, "key")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "missing key")
+		return
+	}
+	ctx, proc := s.procs.register(r.Context(), "delete", r.URL.RawQuery)
+	defer s.procs.unregister(proc)
+	if err := client.GetTiKVClient().Delete(ctx, client.Key(k)); err != nil {
+		writeError(w, errorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, kvItem{Key: string(k)})
+}
+
+func (s *Server) handleProcessList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.procs.list())
+}
+
+func (s *Server) handleKill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if !s.procs.kill(id) {
+		writeError(w, http.StatusNotFound, "no such process")
+		return
+	}
+	writeJSON(w, map[string]uint64{"killed": id})
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/c4pt0r/tcli/client"
+)
+
+func TestHandleScanPrefixPaging(t *testing.T) {
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	var kvs []client.KV
+	for i := 0; i < 2500; i++ {
+		kvs = append(kvs, client.KV{K: client.Key(fmt.Sprintf("user_%05d", i)), V: client.Value("v")})
+	}
+	// keys around the prefix must not be returned
+	kvs = append(kvs, client.KV{K: client.Key("user"), V: client.Value("v")})
+	kvs = append(kvs, client.KV{K: client.Key("usez"), V: client.Value("v")})
+	if err := client.GetTiKVClient().BatchPut(context.TODO(), kvs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"start=user_&prefix=true&limit=10000", 2500},
+		{"start=user_&prefix=true&limit=1500", 1500},
+		{"start=user_&prefix=true&limit=10", 10},
+		{"start=user_01&prefix=true&limit=10000", 1000},
+		{"start=nouser_&prefix=true&limit=10000", 0},
+		{"start=user_&limit=10000", 2501},
+	}
+	s := NewServer(Config{})
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/scan?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		got := 0
+		sc := bufio.NewScanner(w.Body)
+		for sc.Scan() {
+			var item kvItem
+			if err := json.Unmarshal(sc.Bytes(), &item); err != nil || item.Key == "" {
+				t.Fatalf("%s: unexpected line %q", tt.query, sc.Text())
+			}
+			got++
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d keys, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGetStatus(t *testing.T) {
+	if _, err := client.InitMemClient(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.GetTiKVClient().Put(context.TODO(), client.KV{K: client.Key("get_k"), V: client.Value("v")}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"key=get_k", http.StatusOK},
+		{"key=get_missing", http.StatusNotFound},
+		{"", http.StatusBadRequest},
+	}
+	s := NewServer(Config{})
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/get?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.query, w.Code, tt.want)
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("dial tcp 127.0.0.1:2379: connect: connection refused"), http.StatusServiceUnavailable},
+		{errors.New("rpc error: code = Unavailable"), http.StatusServiceUnavailable},
+		{errors.New("write conflict"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := errorStatus(tt.err); got != tt.want {
+			t.Errorf("%v: got %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
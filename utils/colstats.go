@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"strconv"
+)
+
+// With sys.column_stats="on", results of key-value pairs are followed by a
+// table of statistics for every printed column: the empty or null cells,
+// the distinct values and the smallest and largest values. Columns holding
+// numbers only are compared as numbers, anything else as strings.
+
+// the longest min or max value shown
+const colStatsValueWidth = 32
+
+// ColumnStatsEnabled tells if sys.column_stats is on
+func ColumnStatsEnabled() bool {
+	v, _ := SysVarGet(SysVarColumnStatsKey)
+	return v == "on"
+}
+
+type columnStats struct {
+	nulls    int
+	distinct map[string]struct{}
+	numeric  bool
+	min, max string
+	minN     float64
+	maxN     float64
+}
+
+func (s *columnStats) add(v string) {
+	if v == "" || v == "null" {
+		s.nulls++
+		return
+	}
+	first := len(s.distinct) == 0
+	s.distinct[v] = struct{}{}
+	if n, err := strconv.ParseFloat(v, 64); err == nil && (first || s.numeric) {
+		s.numeric = true
+		if first || n < s.minN {
+			s.minN = n
+		}
+		if first || n > s.maxN {
+			s.maxN = n
+		}
+	} else {
+		s.numeric = false
+	}
+	if first || v < s.min {
+		s.min = v
+	}
+	if first || v > s.max {
+		s.max = v
+	}
+}
+
+func (s *columnStats) bounds() (string, string) {
+	if len(s.distinct) == 0 {
+		return "", ""
+	}
+	if s.numeric {
+		return strconv.FormatFloat(s.minN, 'g', -1, 64), strconv.FormatFloat(s.maxN, 'g', -1, 64)
+	}
+	cut := func(v string) string {
+		if r := []rune(v); len(r) > colStatsValueWidth {
+			return string(r[:colStatsValueWidth-3]) + "..."
+		}
+		return v
+	}
+	return cut(s.min), cut(s.max)
+}
+
+// PrintColumnStats prints the statistics of the columns of data selected
+// by --columns, data[0] is the header
+func PrintColumnStats(data [][]string) {
+	data, err := selectColumns(data, outputColumns)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	stats := make([]*columnStats, len(data[0]))
+	for i := range stats {
+		stats[i] = &columnStats{distinct: make(map[string]struct{})}
+	}
+	for _, row := range data[1:] {
+		for i, cell := range row {
+			if i < len(stats) {
+				stats[i].add(cell)
+			}
+		}
+	}
+	output := [][]string{{"Column", "Nulls", "Distinct", "Min", "Max"}}
+	for i, s := range stats {
+		min, max := s.bounds()
+		output = append(output, []string{
+			data[0][i],
+			strconv.Itoa(s.nulls),
+			strconv.Itoa(len(s.distinct)),
+			min,
+			max,
+		})
+	}
+	renderTable(output, true)
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abiosoft/ishell"
+)
+
+// OutputColumnsOpt selects and orders the columns of table output:
+//
+//	.stores --columns=address,state
+//	scanp user_ --columns value,key
+const OutputColumnsOpt = "--columns"
+
+// columns selected for the running command, nil means all columns
+var outputColumns []string
+
+// ExtractOutputColumns strips --columns=a,b or --columns a,b from the
+// command args and returns the column names.
+func ExtractOutputColumns(ic *ishell.Context) ([]string, error) {
+	var value string
+	found := false
+	stripArgs := func(args []string) []string {
+		ret := make([]string, 0, len(args))
+		for i := 0; i < len(args); i++ {
+			switch {
+			case args[i] == OutputColumnsOpt:
+				if i+1 < len(args) {
+					value = args[i+1]
+					i++
+				}
+				found = true
+			case strings.HasPrefix(args[i], OutputColumnsOpt+"="):
+				value = strings.TrimPrefix(args[i], OutputColumnsOpt+"=")
+				found = true
+			default:
+				ret = append(ret, args[i])
+			}
+		}
+		return ret
+	}
+	ic.Args = stripArgs(ic.Args)
+	ic.RawArgs = stripArgs(ic.RawArgs)
+	if !found {
+		return nil, nil
+	}
+	var columns []string
+	for _, col := range strings.Split(value, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, NewParseError("no columns given, usage: --columns=<col1>,<col2>")
+	}
+	return columns, nil
+}
+
+// selectColumns keeps the selected columns of data in the given order,
+// column names are matched case-insensitively against the header
+func selectColumns(data [][]string, columns []string) ([][]string, error) {
+	if len(columns) == 0 || len(data) == 0 {
+		return data, nil
+	}
+	idx := make([]int, len(columns))
+	for i, col := range columns {
+		idx[i] = -1
+		for j, name := range data[0] {
+			if strings.EqualFold(col, name) {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, fmt.Errorf("unknown column: %s, available columns: %s", col, strings.Join(data[0], ", "))
+		}
+	}
+	ret := make([][]string, len(data))
+	for i, row := range data {
+		ret[i] = make([]string, len(idx))
+		for j, k := range idx {
+			if k < len(row) {
+				ret[i][j] = row[k]
+			}
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes of tcli when commands are read from a pipe or a file
+const (
+	ExitOK = 0
+	// a command failed
+	ExitCmdError = 1
+	// unknown command, bad argument or option
+	ExitParseError = 2
+	// the store can't be reached, on startup or by a command
+	ExitConnError = 3
+	// some commands of a script failed, others succeeded
+	ExitPartialFailure = 4
+)
+
+// ParseError is returned for malformed commands, arguments and options
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string { return e.Err.Error() }
+func (e *ParseError) Unwrap() error { return e.Err }
+
+func NewParseError(format string, a ...interface{}) error {
+	return &ParseError{Err: fmt.Errorf(format, a...)}
+}
+
+// errors containing one of these mean the store can't be reached
+var unreachableErrPatterns = []string{
+	"connection refused",
+	"context deadline exceeded",
+	"no route to host",
+	"i/o timeout",
+	"Unavailable",
+	"failed to get cluster id",
+}
+
+func IsUnreachableErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, pattern := range unreachableErrPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExitCodeOf maps a command error to an exit code
+func ExitCodeOf(err error) int {
+	var perr *ParseError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &perr):
+		return ExitParseError
+	case IsUnreachableErr(err):
+		return ExitConnError
+	}
+	return ExitCmdError
+}
+
+// the error of the last command, recorded by OutputWithElapse
+var lastCmdErr error
+
+func SetLastCmdError(err error) {
+	lastCmdErr = err
+}
+
+// TakeLastCmdError returns and clears the error of the last command
+func TakeLastCmdError() error {
+	err := lastCmdErr
+	lastCmdErr = nil
+	return err
+}
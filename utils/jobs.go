@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Bulk commands (loadcsv, backup, delp) started with --bg run as jobs in
+// the background of the session, the shell stays usable meanwhile. Their
+// messages go to the job log instead of stdout.
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobDone      JobState = "done"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// the lines kept in the log of a job
+const jobLogLines = 20
+
+type Job struct {
+	ID  int
+	Cmd string
+
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	state     JobState
+	err       error
+	keys      int64
+	startedAt time.Time
+	endedAt   time.Time
+	// when the last batch was done, throttling paces batches from it
+	lastBatch time.Time
+	log       []string
+}
+
+var (
+	_jobsMu sync.Mutex
+	_jobs   = make(map[int]*Job)
+	_nextID = 1
+)
+
+// StartJob runs fn in the background as the job of cmd
+func StartJob(cmd string, fn func(ctx context.Context, job *Job) error) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	_jobsMu.Lock()
+	job := &Job{
+		ID:        _nextID,
+		Cmd:       cmd,
+		cancel:    cancel,
+		state:     JobRunning,
+		startedAt: time.Now(),
+		lastBatch: time.Now(),
+	}
+	_jobs[job.ID] = job
+	_nextID++
+	_jobsMu.Unlock()
+
+	go func() {
+		defer cancel()
+		err := fn(ctx, job)
+		job.mu.Lock()
+		job.endedAt = time.Now()
+		switch {
+		case err == nil:
+			job.state = JobDone
+		case errors.Is(err, context.Canceled):
+			job.state = JobCancelled
+		default:
+			job.state = JobFailed
+			job.err = err
+		}
+		state := job.state
+		job.mu.Unlock()
+		fmt.Fprintf(os.Stderr, "\nJob %d %s: %s\n", job.ID, state, job.Cmd)
+	}()
+	return job
+}
+
+func GetJob(id int) (*Job, bool) {
+	_jobsMu.Lock()
+	defer _jobsMu.Unlock()
+	job, ok := _jobs[id]
+	return job, ok
+}
+
+// ListJobs returns all jobs of the session, oldest first
+func ListJobs() []*Job {
+	_jobsMu.Lock()
+	defer _jobsMu.Unlock()
+	var jobs []*Job
+	for _, job := range _jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+// Cancel asks the job to stop, it stops at the end of its current batch
+func (j *Job) Cancel() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.state != JobRunning {
+		return fmt.Errorf("job %d is already %s", j.ID, j.state)
+	}
+	j.cancel()
+	return nil
+}
+
+// JobStatus is a snapshot of a job
+type JobStatus struct {
+	State   JobState
+	Err     error
+	Keys    int64
+	Elapsed time.Duration
+	Log     []string
+}
+
+func (j *Job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	end := j.endedAt
+	if j.state == JobRunning {
+		end = time.Now()
+	}
+	return JobStatus{
+		State:   j.state,
+		Err:     j.err,
+		Keys:    j.keys,
+		Elapsed: end.Sub(j.startedAt),
+		Log:     append([]string{}, j.log...),
+	}
+}
+
+// Print writes a message to the job log, or to stdout if j is nil, so that
+// commands print the same way in the foreground
+func (j *Job) Print(a ...interface{}) {
+	if j == nil {
+		Print(a...)
+		return
+	}
+	msg := fmt.Sprintln(a...)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.log = append(j.log, time.Now().Format("15:04:05 ")+msg[:len(msg)-1])
+	if len(j.log) > jobLogLines {
+		j.log = j.log[len(j.log)-jobLogLines:]
+	}
+}
+
+// Done records n more keys done by the job, then waits as long as needed
+// to keep the job under sys.job_rate_limit keys per second. It returns the
+// error of ctx once the job is cancelled. Foreground commands (a nil j)
+// are never throttled.
+func (j *Job) Done(ctx context.Context, n int) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	j.keys += int64(n)
+	last := j.lastBatch
+	j.mu.Unlock()
+
+	// a batch of n keys takes at least n / rate seconds
+	if rate := SysVarGetInt(SysVarJobRateLimitKey, 0); rate > 0 {
+		least := time.Duration(float64(n) / float64(rate) * float64(time.Second))
+		if wait := least - time.Since(last); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+			}
+		}
+	}
+	j.mu.Lock()
+	j.lastBatch = time.Now()
+	j.mu.Unlock()
+	return ctx.Err()
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Values holding a JSON object or array are rendered in table output
+// following sys.json_render:
+//
+//	pretty   indented, at most jsonRenderMaxLines lines (the default)
+//	compact  on one line, at most jsonRenderMaxWidth characters
+//	raw      as stored
+const (
+	JSONRenderPretty  = "pretty"
+	JSONRenderCompact = "compact"
+	JSONRenderRaw     = "raw"
+)
+
+const (
+	jsonRenderMaxLines = 20
+	jsonRenderMaxWidth = 80
+)
+
+var (
+	jsonKeyColor    = color.New(color.FgBlue, color.Bold).SprintFunc()
+	jsonStringColor = color.New(color.FgGreen).SprintFunc()
+	jsonNumberColor = color.New(color.FgCyan).SprintFunc()
+	jsonLitColor    = color.New(color.FgYellow).SprintFunc()
+)
+
+// JSONRenderMode returns sys.json_render, pretty if it's not a known mode
+func JSONRenderMode() string {
+	mode, _ := SysVarGet(SysVarJSONRenderKey)
+	switch mode {
+	case JSONRenderCompact, JSONRenderRaw:
+		return mode
+	}
+	return JSONRenderPretty
+}
+
+// RenderJSONValue renders v for a table cell if it's a JSON object or array,
+// returns false for anything else, scalars like 123 or "abc" included.
+// Syntax colors are only added if colored is set and stdout is a terminal.
+func RenderJSONValue(v []byte, mode string, colored bool) (string, bool) {
+	if mode == JSONRenderRaw {
+		return "", false
+	}
+	trimmed := bytes.TrimSpace(v)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid(trimmed) {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if mode == JSONRenderCompact {
+		json.Compact(&buf, trimmed)
+		s := buf.String()
+		more := ""
+		if r := []rune(s); len(r) > jsonRenderMaxWidth {
+			s = string(r[:jsonRenderMaxWidth-3])
+			more = "..."
+		}
+		if colored {
+			s = colorizeJSON(s)
+		}
+		return s + more, true
+	}
+	json.Indent(&buf, trimmed, "", "  ")
+	lines := strings.Split(buf.String(), "\n")
+	more := ""
+	if len(lines) > jsonRenderMaxLines {
+		more = fmt.Sprintf("\n... (%d more lines)", len(lines)-jsonRenderMaxLines+1)
+		lines = lines[:jsonRenderMaxLines-1]
+	}
+	s := strings.Join(lines, "\n")
+	if colored {
+		s = colorizeJSON(s)
+	}
+	return s + more, true
+}
+
+// colorizeJSON adds syntax colors to formatted JSON, s may be cut anywhere
+func colorizeJSON(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		ch := s[i]
+		switch {
+		case ch == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(s) {
+				j++
+			} else {
+				j = len(s)
+			}
+			// a string followed by ':' is an object key
+			k := j
+			for k < len(s) && (s[k] == ' ' || s[k] == '\t') {
+				k++
+			}
+			if k < len(s) && s[k] == ':' {
+				sb.WriteString(jsonKeyColor(s[i:j]))
+			} else {
+				sb.WriteString(jsonStringColor(s[i:j]))
+			}
+			i = j
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			sb.WriteString(jsonNumberColor(s[i:j]))
+			i = j
+		case ch >= 'a' && ch <= 'z':
+			j := i + 1
+			for j < len(s) && s[j] >= 'a' && s[j] <= 'z' {
+				j++
+			}
+			sb.WriteString(jsonLitColor(s[i:j]))
+			i = j
+		default:
+			sb.WriteByte(ch)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// FlattenJSONObject returns the top-level fields of v in their order in v
+// and their values, strings unquoted and anything else as compact JSON.
+// It returns false if v is not a JSON object.
+func FlattenJSONObject(v []byte) ([]string, map[string]string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(v))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+	var fields []string
+	values := make(map[string]string)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return nil, nil, false
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, false
+		}
+		if _, ok := values[name]; !ok {
+			fields = append(fields, name)
+		}
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			values[name] = s
+			continue
+		}
+		var buf bytes.Buffer
+		json.Compact(&buf, raw)
+		values[name] = buf.String()
+	}
+	// the closing '}' and nothing after it
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, false
+	}
+	return fields, values, true
+}
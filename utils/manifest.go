@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// JobManifest records the progress of a bulk job (backup, loadcsv) next to
+// its data file, so an interrupted job can be resumed and a finished one
+// verified. It's rewritten after every batch.
+type JobManifest struct {
+	Version int    `json:"version"`
+	Job     string `json:"job"`
+	// the data file written by backup or read by loadcsv
+	File string `json:"file"`
+	// what the job was started with, a resumed job must match
+	Settings map[string]string `json:"settings"`
+	// one range per run of the job, the first and the last key done
+	Ranges []ManifestRange `json:"ranges"`
+	// kv pairs done and the CRC32 (Castagnoli) of them in job order
+	Keys     int64  `json:"keys"`
+	Checksum uint32 `json:"checksum"`
+	// backup: the size of the data file after the last batch
+	FileSize int64 `json:"file_size,omitempty"`
+	// loadcsv: the csv records consumed, skipped rows included
+	Records   int64     `json:"records,omitempty"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ManifestRange struct {
+	// string literals, like the arguments of commands
+	FirstKey string `json:"first_key"`
+	LastKey  string `json:"last_key"`
+	Keys     int64  `json:"keys"`
+}
+
+const (
+	ManifestVersion = 1
+	ManifestSuffix  = ".manifest"
+)
+
+var manifestCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+func ManifestPath(dataFile string) string {
+	return dataFile + ManifestSuffix
+}
+
+func NewJobManifest(job, dataFile string, settings map[string]string) *JobManifest {
+	return &JobManifest{
+		Version:  ManifestVersion,
+		Job:      job,
+		File:     dataFile,
+		Settings: settings,
+	}
+}
+
+// LoadJobManifest reads the manifest of dataFile, returns nil if there is
+// none
+func LoadJobManifest(dataFile string) (*JobManifest, error) {
+	fname := ManifestPath(dataFile)
+	buf, err := ioutil.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m JobManifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", fname, err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s", m.Version, fname)
+	}
+	return &m, nil
+}
+
+// Save replaces the manifest file, a crash never leaves it half written
+func (m *JobManifest) Save() error {
+	m.UpdatedAt = time.Now()
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	fname := ManifestPath(m.File)
+	tmp := fname + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fname)
+}
+
+// CheckResume returns an error if the job of m can't be resumed as job with
+// settings
+func (m *JobManifest) CheckResume(job string, settings map[string]string) error {
+	if m.Job != job {
+		return fmt.Errorf("%s was written by %s, not %s", ManifestPath(m.File), m.Job, job)
+	}
+	if m.Done {
+		return fmt.Errorf("the %s of %s is already complete", m.Job, m.File)
+	}
+	var keys []string
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if m.Settings[k] != settings[k] {
+			return fmt.Errorf("can't resume with %s %s, the job was started with %s", k, settings[k], m.Settings[k])
+		}
+	}
+	return nil
+}
+
+// StartRun starts a new range, called when the job is started or resumed
+func (m *JobManifest) StartRun() {
+	m.Ranges = append(m.Ranges, ManifestRange{})
+}
+
+// LastKey returns the last key done, nil if there is none
+func (m *JobManifest) LastKey() ([]byte, error) {
+	for i := len(m.Ranges) - 1; i >= 0; i-- {
+		if m.Ranges[i].Keys > 0 {
+			return GetStringLit(m.Ranges[i].LastKey)
+		}
+	}
+	return nil, nil
+}
+
+// AddPair records a kv pair done by the current run
+func (m *JobManifest) AddPair(k, v []byte) {
+	r := &m.Ranges[len(m.Ranges)-1]
+	if r.Keys == 0 {
+		r.FirstKey = Bytes2ReadableStrLit(k)
+	}
+	r.LastKey = Bytes2ReadableStrLit(k)
+	r.Keys++
+	m.Keys++
+	m.Checksum = ChecksumKV(m.Checksum, k, v)
+}
+
+// ChecksumKV adds a kv pair to crc, lengths are included so that moving
+// bytes between a key and its value changes the checksum
+func ChecksumKV(crc uint32, k, v []byte) uint32 {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(k)))
+	crc = crc32.Update(crc, manifestCRCTable, lenBuf[:n])
+	crc = crc32.Update(crc, manifestCRCTable, k)
+	n = binary.PutUvarint(lenBuf[:], uint64(len(v)))
+	crc = crc32.Update(crc, manifestCRCTable, lenBuf[:n])
+	return crc32.Update(crc, manifestCRCTable, v)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abiosoft/ishell"
+	"github.com/fatih/color"
+)
+
+// sys.max_result_rows and sys.max_result_bytes cap the key-value pairs
+// printed by a command, 0 means no cap. NoTruncateOpt lifts both for one
+// command, for intentional large dumps:
+//
+//	scanp log_ --limit=1000000 --no-truncate \o logs.json
+const NoTruncateOpt = "--no-truncate"
+
+// set while the running command was given --no-truncate
+var noTruncate bool
+
+// ExtractNoTruncate strips --no-truncate from the command args and returns
+// whether it was given
+func ExtractNoTruncate(ic *ishell.Context) bool {
+	found := false
+	strip := func(args []string) []string {
+		ret := make([]string, 0, len(args))
+		for _, arg := range args {
+			if arg == NoTruncateOpt {
+				found = true
+				continue
+			}
+			ret = append(ret, arg)
+		}
+		return ret
+	}
+	ic.Args = strip(ic.Args)
+	ic.RawArgs = strip(ic.RawArgs)
+	return found
+}
+
+// ResultQuota returns how many of the n pairs fit the caps and the
+// variable of the cap that was hit, size returns the size of the i-th pair
+func ResultQuota(n int, size func(i int) int) (int, string) {
+	if noTruncate {
+		return n, ""
+	}
+	by := ""
+	if maxRows := SysVarGetInt(SysVarMaxResultRowsKey, 0); maxRows > 0 && n > maxRows {
+		n, by = maxRows, SysVarMaxResultRowsKey
+	}
+	if maxBytes := SysVarGetInt(SysVarMaxResultBytesKey, 0); maxBytes > 0 {
+		total := 0
+		for i := 0; i < n; i++ {
+			total += size(i)
+			if total > maxBytes {
+				return i, SysVarMaxResultBytesKey
+			}
+		}
+	}
+	return n, by
+}
+
+// PrintTruncatedFooter warns that only shown of total pairs were printed
+// because of the cap of the variable by
+func PrintTruncatedFooter(shown, total int, by string) {
+	fmt.Fprintln(os.Stderr, color.YellowString("Output truncated to %d of %d records by %s, add %s to print all",
+		shown, total, by, NoTruncateOpt))
+}
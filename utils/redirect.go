@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/abiosoft/ishell"
+)
+
+// Output redirection for a single command:
+//
+//	scanp user_ \o result.json     truncate result.json and write to it
+//	scanp user_ \o+ result.json    append to result.json
+//	scanp user_ \copy              put the result onto the system clipboard
+const (
+	RedirectTruncate  = `\o`
+	RedirectAppend    = `\o+`
+	RedirectClipboard = `\copy`
+)
+
+type OutputRedirect struct {
+	File      string
+	Append    bool
+	Clipboard bool
+}
+
+// ExtractOutputRedirect strips a trailing `\o <file>`, `\o+ <file>` or
+// `\copy` from the command args, returns nil if the output is not redirected.
+func ExtractOutputRedirect(ic *ishell.Context) (*OutputRedirect, error) {
+	n := len(ic.RawArgs)
+	if n < 2 {
+		return nil, nil
+	}
+	if ic.RawArgs[n-1] == RedirectClipboard {
+		ic.RawArgs = ic.RawArgs[:n-1]
+		if len(ic.Args) >= 1 {
+			ic.Args = ic.Args[:len(ic.Args)-1]
+		}
+		return &OutputRedirect{Clipboard: true}, nil
+	}
+	r := &OutputRedirect{File: ic.RawArgs[n-1]}
+	switch ic.RawArgs[n-2] {
+	case RedirectTruncate:
+	case RedirectAppend:
+		r.Append = true
+	default:
+		if ic.RawArgs[n-1] == RedirectTruncate || ic.RawArgs[n-1] == RedirectAppend {
+			return nil, NewParseError("missing output file name")
+		}
+		return nil, nil
+	}
+	ic.RawArgs = ic.RawArgs[:n-2]
+	if len(ic.Args) >= 2 {
+		ic.Args = ic.Args[:len(ic.Args)-2]
+	}
+	return r, nil
+}
+
+// clipboardCmd returns the command that copies its stdin to the clipboard
+func clipboardCmd() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbcopy"), nil
+	case "windows":
+		return exec.Command("clip"), nil
+	}
+	candidates := [][]string{
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = candidates[1:]
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return exec.Command(c[0], c[1:]...), nil
+		}
+	}
+	return nil, errors.New("no clipboard tool found, install xclip, xsel or wl-copy")
+}
+
+func copyToClipboard(fp *os.File) error {
+	cmd, err := clipboardCmd()
+	if err != nil {
+		return err
+	}
+	if _, err := fp.Seek(0, 0); err != nil {
+		return err
+	}
+	cmd.Stdin = fp
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RunWithOutputOptions runs f with the output options given in the args of
+// ic applied: column selection, --no-truncate and output redirection.
+func RunWithOutputOptions(ic *ishell.Context, f func()) error {
+	if ExtractNoTruncate(ic) {
+		noTruncate = true
+		defer func() { noTruncate = false }()
+	}
+	columns, err := ExtractOutputColumns(ic)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		outputColumns = columns
+		defer func() { outputColumns = nil }()
+	}
+	return RunWithOutputRedirect(ic, f)
+}
+
+// RunWithOutputRedirect runs f with stdout redirected as requested by the
+// trailing args of ic, messages on stderr are not redirected.
+func RunWithOutputRedirect(ic *ishell.Context, f func()) error {
+	r, err := ExtractOutputRedirect(ic)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		f()
+		return nil
+	}
+	var fp *os.File
+	if r.Clipboard {
+		// look up the tool first, so the command is not run for nothing
+		if _, err := clipboardCmd(); err != nil {
+			return err
+		}
+		fp, err = ioutil.TempFile("", "tcli-copy-")
+		if err == nil {
+			defer os.Remove(fp.Name())
+		}
+	} else {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if r.Append {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		fp, err = os.OpenFile(r.File, flags, 0644)
+	}
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	func() {
+		stdout := os.Stdout
+		os.Stdout = fp
+		defer func() { os.Stdout = stdout }()
+		f()
+	}()
+	if r.Clipboard {
+		return copyToClipboard(fp)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+// FormatTS prints a TSO with its local time, "none" for 0
+func FormatTS(ts uint64) string {
+	if ts == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d (%s)", ts, oracle.GetTimeFromTS(ts).Format("2006-01-02 15:04:05"))
+}
+
+// ParseTS accepts a TSO, a local time or a duration before now
+func ParseTS(s string, now uint64) (uint64, error) {
+	if ts, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local); err == nil {
+		return oracle.GoTimeToTS(t), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return oracle.GoTimeToTS(oracle.GetTimeFromTS(now).Add(-d)), nil
+	}
+	return 0, NewParseError("invalid timestamp %q, should be a TSO, a time or a duration", s)
+}
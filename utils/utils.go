@@ -5,8 +5,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -22,18 +24,99 @@ var (
 	propertiesKey = "property"
 )
 
+// PrintTable prints data[0] as the header and the rest as rows, the output
+// follows sys.printfmt for markdown and html, anything else is a text table
 func PrintTable(data [][]string) {
+	printTable(data, true)
+}
+
+// PrintPreformattedTable is PrintTable for cells formatted by the caller,
+// like indented JSON, they're printed line by line without wrapping
+func PrintPreformattedTable(data [][]string) {
+	printTable(data, false)
+}
+
+func printTable(data [][]string, wrap bool) {
+	data, err := selectColumns(data, outputColumns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\033[31mError: %s\033[0m\n", err)
+		return
+	}
+	renderTable(data, wrap)
+}
+
+// renderTable prints data as is, the columns are already selected
+func renderTable(data [][]string, wrap bool) {
+	if f, ok := SysVarGet(SysVarPrintFormatKey); ok {
+		switch string(f) {
+		case "markdown":
+			printMarkdownTable(os.Stdout, data)
+			return
+		case "html":
+			printHTMLTable(os.Stdout, data)
+			return
+		}
+	}
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader(data[0])
 	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
 	table.SetCenterSeparator("|")
+	table.SetAutoWrapText(wrap)
 	table.AppendBulk(data[1:])
 	table.Render()
 }
 
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func printMarkdownTable(w io.Writer, data [][]string) {
+	for i, row := range data {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = escapeMarkdownCell(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		if i == 0 {
+			fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(row)))
+		}
+	}
+}
+
+func printHTMLTable(w io.Writer, data [][]string) {
+	fmt.Fprintln(w, "<table>")
+	for i, row := range data {
+		tag := "td"
+		if i == 0 {
+			fmt.Fprintln(w, "<thead>")
+			tag = "th"
+		} else if i == 1 {
+			fmt.Fprintln(w, "<tbody>")
+		}
+		fmt.Fprint(w, "<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(w, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+		}
+		fmt.Fprintln(w, "</tr>")
+		if i == 0 {
+			fmt.Fprintln(w, "</thead>")
+		}
+	}
+	if len(data) > 1 {
+		fmt.Fprintln(w, "</tbody>")
+	}
+	fmt.Fprintln(w, "</table>")
+}
+
 func OutputWithElapse(f func() error) error {
 	tt := time.Now()
 	err := f()
+	SetLastCmdError(err)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "\033[31mError: %s\033[0m\nElapse: %d ms\n", err, time.Since(tt)/time.Millisecond)
 	} else {
@@ -63,6 +146,17 @@ func Bytes2StrLit(b []byte) string {
 	return fmt.Sprintf("h'%s'", Bytes2hex(b))
 }
 
+// Bytes2ReadableStrLit returns a quoted string literal if b is printable
+// ascii without quotes or backslashes, or a hex string literal otherwise
+func Bytes2ReadableStrLit(b []byte) string {
+	for _, ch := range b {
+		if ch < 0x20 || ch > 0x7e || ch == '"' || ch == '\'' || ch == '\\' {
+			return Bytes2StrLit(b)
+		}
+	}
+	return fmt.Sprintf("\"%s\"", b)
+}
+
 var (
 	_reHexStr, _reNormalStr *regexp.Regexp
 )
@@ -78,7 +172,7 @@ func IsStringLit(raw string) bool {
 
 func GetStringLit(raw string) ([]byte, error) {
 	if strings.HasPrefix(raw, "--") {
-		return nil, fmt.Errorf("wrong format: [%s], it seems a option flag?", raw)
+		return nil, NewParseError("wrong format: [%s], it seems a option flag?", raw)
 	}
 	if raw[0] == '$' {
 		varVal, ok := VarGet(raw[1:])
@@ -93,7 +187,7 @@ func GetStringLit(raw string) ([]byte, error) {
 		val := string(out[2 : len(out)-1])
 		b, err := Hexstr2bytes(val)
 		if err != nil {
-			return nil, err
+			return nil, &ParseError{Err: err}
 		}
 		return b, nil
 	}
@@ -127,7 +221,7 @@ func SetOptByString(ss []string, props *properties.Properties) error {
 				}
 			}
 		} else {
-			return fmt.Errorf("wrong flag format: [%s] ", flag)
+			return NewParseError("wrong flag format: [%s] ", flag)
 		}
 	}
 	return nil
@@ -274,7 +368,7 @@ func HasForceYes(ctx context.Context) bool {
 	ic := ExtractIshellContext(ctx)
 	_, flags := GetArgsAndOptionFlag(ic.Args)
 	for _, flag := range flags {
-		if flag == "--yes" {
+		if flag == "--yes" || flag == "--force" {
 			return true
 		}
 	}
@@ -290,6 +384,20 @@ func ExtractIshellContext(ctx context.Context) *ishell.Context {
 	return ic
 }
 
+// PrefixNextKey returns the smallest key greater than all keys with prefix,
+// nil if there is none (the prefix is empty or all 0xff).
+func PrefixNextKey(prefix []byte) []byte {
+	buf := make([]byte, len(prefix))
+	copy(buf, prefix)
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i]++
+		if buf[i] != 0 {
+			return buf[:i+1]
+		}
+	}
+	return nil
+}
+
 // NextKey returns the next key in byte-order.
 func NextKey(k []byte) []byte {
 	// add 0x0 to the end of key
@@ -297,3 +405,31 @@ func NextKey(k []byte) []byte {
 	copy(buf, k)
 	return buf
 }
+
+var cmdLineRunner func(line string) error
+
+// SetCmdLineRunner sets the function used by RunCmdLine, it's set by the shell
+func SetCmdLineRunner(f func(line string) error) {
+	cmdLineRunner = f
+}
+
+// RunCmdLine runs a full command line, e.g. `scan "a" --limit=10`
+func RunCmdLine(line string) error {
+	if cmdLineRunner == nil {
+		return errors.New("no command runner")
+	}
+	return cmdLineRunner(line)
+}
+
+// ConfigDir returns the tcli config directory, creating it if needed
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "tcli")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -2,12 +2,23 @@ package utils
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 var (
-	SysVarPrintFormatKey string = "sys.printfmt"
+	SysVarPrintFormatKey      string = "sys.printfmt"
+	SysVarConfirmThresholdKey string = "sys.confirm_threshold"
+	SysVarReadPolicyKey       string = "sys.read_policy"
+	SysVarJobRateLimitKey     string = "sys.job_rate_limit"
+	SysVarJSONRenderKey       string = "sys.json_render"
+	SysVarMaxResultRowsKey    string = "sys.max_result_rows"
+	SysVarMaxResultBytesKey   string = "sys.max_result_bytes"
+	SysVarColumnStatsKey      string = "sys.column_stats"
+	SysVarKeepaliveKey        string = "sys.keepalive_interval"
+	SysVarPromptKey           string = "sys.prompt"
 )
 
 var (
@@ -21,6 +32,15 @@ var (
 	_globalSysVariables = make(map[string]string)
 	_builtinSysVars     = [][]string{
 		{SysVarPrintFormatKey, "table"},
+		{SysVarConfirmThresholdKey, "0"},
+		{SysVarReadPolicyKey, "primary"},
+		{SysVarJobRateLimitKey, "0"},
+		{SysVarJSONRenderKey, JSONRenderPretty},
+		{SysVarMaxResultRowsKey, "0"},
+		{SysVarMaxResultBytesKey, "0"},
+		{SysVarColumnStatsKey, "off"},
+		{SysVarKeepaliveKey, "30s"},
+		{SysVarPromptKey, ""},
 	}
 )
 
@@ -61,6 +81,7 @@ func PrintGlobalVaribles() {
 			vv := fmt.Sprintf("h'%s'", Bytes2hex(v))
 			data = append(data, []string{k, vv})
 		}
+		sort.Slice(data[1:], func(i, j int) bool { return data[i+1][0] < data[j+1][0] })
 		PrintTable(data)
 	}
 }
@@ -72,6 +93,19 @@ func SysVarGet(varname string) (string, bool) {
 	return val, ok
 }
 
+// SysVarGetInt returns def if the variable is not set or not a number
+func SysVarGetInt(varname string, def int) int {
+	val, ok := SysVarGet(varname)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
 func SysVarSet(varname, val string) {
 	_varMutex.Lock()
 	defer _varMutex.Unlock()
@@ -88,6 +122,7 @@ func PrintSysVaribles() {
 		for k, v := range _globalSysVariables {
 			data = append(data, []string{k, string(v)})
 		}
+		sort.Slice(data[1:], func(i, j int) bool { return data[i+1][0] < data[j+1][0] })
 		PrintTable(data)
 	}
 }